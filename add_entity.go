@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/dawit-go/small-go/lockfile"
+	"github.com/dawit-go/small-go/scaffold"
+	"github.com/dawit-go/small-go/templates"
+	"github.com/dawit-go/small-go/wiring"
+)
+
+// addEntity generates an entity across all layers of an existing project and,
+// unless wire is false, registers it in the project's existing files
+func addEntity(projectDir, entityName, fields string, wire bool) error {
+	entity, err := templates.ParseEntity(entityName, fields)
+	if err != nil {
+		return err
+	}
+
+	modulePath, err := readModulePath(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Projects generated before lockfiles existed fall back to detection
+	lock, err := lockfile.Read(projectDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var template templates.Template
+	if lock != nil {
+		if template, err = lockedTemplate(lock); err != nil {
+			return err
+		}
+	} else if template, err = detectTemplate(projectDir, modulePath); err != nil {
+		return err
+	}
+	generator, ok := templates.GetEntityGenerator(template)
+	if !ok {
+		return fmt.Errorf("template %s does not support adding entities", template.Name())
+	}
+
+	files, edits, err := scaffold.RenderEntity(generator, modulePath, entity)
+	if err != nil {
+		return err
+	}
+	paths := sortedPaths(files)
+
+	// Refuse to overwrite anything before writing the first file
+	for _, filePath := range paths {
+		if _, err := os.Stat(filepath.Join(projectDir, filePath)); err == nil {
+			return fmt.Errorf("%s already exists", filePath)
+		}
+	}
+
+	// Work out every registration up front so that nothing is written
+	// if one of the existing files cannot be updated
+	var changes map[string][]byte
+	if wire {
+		changes, err = wiring.Plan(projectDir, edits)
+		if err != nil {
+			return fmt.Errorf("cannot register %s, nothing was written (use --no-wire to only generate the files):\n%w", entity.Name, err)
+		}
+	}
+
+	// Generated files the user has not touched stay unmodified after wiring;
+	// files the user already changed must keep showing up as modified
+	var pristine []string
+	if lock != nil {
+		for filePath := range changes {
+			if modified, err := lock.Modified(projectDir, filePath); err == nil && !modified {
+				pristine = append(pristine, filePath)
+			}
+		}
+	}
+
+	for _, filePath := range paths {
+		content := files[filePath]
+		if err := writeFile(filepath.Join(projectDir, filePath), content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+		if lock != nil {
+			lock.Record(filePath, []byte(content))
+		}
+		fmt.Printf("  created %s\n", filePath)
+	}
+
+	if wire {
+		updated, err := wiring.Write(projectDir, changes)
+		if err != nil {
+			return err
+		}
+		for _, filePath := range updated {
+			fmt.Printf("  updated %s\n", filePath)
+		}
+	}
+
+	if lock != nil {
+		for _, filePath := range pristine {
+			lock.Record(filePath, changes[filePath])
+		}
+		lock.Entities = append(lock.Entities, lockfile.Entity{Name: entity.Name, Fields: fields})
+		if err := lock.Write(projectDir); err != nil {
+			return fmt.Errorf("failed to update %s: %w", lockfile.FileName, err)
+		}
+	}
+
+	if err := updateEntityBase(projectDir, files, edits); err != nil {
+		return fmt.Errorf("failed to update %s: %w", lockfile.BaseDir, err)
+	}
+
+	// The new files may import packages the vendor directory lacks so far
+	if lock != nil && lock.Vendor {
+		if err := runGoModVendor(context.Background(), projectDir); err != nil {
+			return fmt.Errorf("failed to run go mod vendor: %w", err)
+		}
+	}
+
+	if !wire {
+		fmt.Println()
+		fmt.Printf("Register %s in the existing files:\n", entity.Name)
+		for _, edit := range edits {
+			fmt.Printf("  %s\n", edit)
+		}
+	}
+
+	return nil
+}
+
+// updateEntityBase keeps the base copies used by small-go upgrade in line
+// with an added entity: its files are added and the registrations are applied
+// to the generated content of the wired files, not to the user's version
+func updateEntityBase(projectDir string, files map[string]string, edits []wiring.Edit) error {
+	if _, err := os.Stat(filepath.Join(projectDir, lockfile.BaseDir)); os.IsNotExist(err) {
+		return nil
+	}
+
+	base := make(map[string][]byte)
+	for _, edit := range edits {
+		content, err := lockfile.ReadBase(projectDir, edit.File)
+		if err != nil {
+			continue
+		}
+		base[edit.File] = []byte(content)
+	}
+
+	updated := make(map[string]string, len(files))
+	for filePath, content := range files {
+		updated[filePath] = content
+	}
+	// The generated content may lack an anchor the user added by hand; the
+	// upgrade then treats the registration as the user's own change
+	if changes, err := wiring.PlanFiles(base, edits); err == nil {
+		for filePath, content := range changes {
+			updated[filePath] = string(content)
+		}
+	}
+	return lockfile.UpdateBase(projectDir, updated)
+}
+
+// readModulePath returns the module path declared in the project's go.mod
+func readModulePath(projectDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read go.mod, is %s a Go project? %w", projectDir, err)
+	}
+	modulePath := modfile.ModulePath(content)
+	if modulePath == "" {
+		return "", fmt.Errorf("go.mod in %s declares no module", projectDir)
+	}
+	return modulePath, nil
+}
+
+// detectTemplate finds the template whose generated files best match the
+// project. Only templates that can add entities are rendered, so template
+// plugins on PATH are never run to find out.
+func detectTemplate(projectDir, modulePath string) (templates.Template, error) {
+	var best templates.Template
+	bestScore := 0.0
+
+	for _, template := range templates.GetAvailableTemplates() {
+		if _, ok := templates.GetEntityGenerator(template); !ok {
+			continue
+		}
+		files, err := templates.RenderFiles(template, templates.Params{ProjectName: modulePath})
+		if err != nil || len(files) == 0 {
+			continue
+		}
+
+		found := 0
+		for filePath := range files {
+			if _, err := os.Stat(filepath.Join(projectDir, filePath)); err == nil {
+				found++
+			}
+		}
+
+		score := float64(found) / float64(len(files))
+		if score > bestScore {
+			best, bestScore = template, score
+		}
+	}
+
+	// Most generated files are expected to still be in place
+	if best == nil || bestScore < 0.5 {
+		return nil, fmt.Errorf("could not detect the template %s was generated from", projectDir)
+	}
+	return best, nil
+}
+
+// sortedPaths returns the paths of a file map in sorted order
+func sortedPaths(files map[string]string) []string {
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -1,77 +1,68 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
+	"github.com/dawit-go/small-go/scaffold"
 	"github.com/dawit-go/small-go/templates"
 )
 
-// createProject creates a new Go project with the selected template
-func createProject(projectName, templateName string) error {
-	// Get the selected template
-	template := templates.GetTemplateByName(templateName)
-	if template == nil {
-		return fmt.Errorf("unknown template: %s. Use 'small-go list' to see available templates", templateName)
-	}
+// goRunner runs go commands with their output shown to the user
+var goRunner = scaffold.ExecRunner{Stdout: os.Stdout, Stderr: os.Stderr}
 
-	// Create project directory
-	if err := os.MkdirAll(projectName, 0755); err != nil {
-		return fmt.Errorf("failed to create project directory: %w", err)
-	}
-
-	// Change to project directory
-	if err := os.Chdir(projectName); err != nil {
-		return fmt.Errorf("failed to change to project directory: %w", err)
-	}
-
-	// Initialize Go module
-	if err := runGoModInit(projectName); err != nil {
-		return fmt.Errorf("failed to initialize Go module: %w", err)
-	}
+// createOptions are the flags of small-go new that change how a project is set up
+type createOptions struct {
+	// offline resolves dependencies from the local module cache only
+	offline bool
+	// vendor vendors the dependencies of the project
+	vendor bool
+	// features are added on top of the template
+	features []string
+}
 
-	// Generate files using the selected template
-	if err := generateTemplateFiles(projectName, template); err != nil {
-		return fmt.Errorf("failed to generate files: %w", err)
+// createProject creates a new Go project with the selected template
+func createProject(ctx context.Context, templateName string, params templates.Params, opts createOptions) error {
+	template, err := selectedTemplate(templateName, opts.features)
+	if err != nil {
+		return err
 	}
 
-	// Run go mod tidy
-	if err := runGoModTidy(); err != nil {
-		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	runner := goRunner
+	if opts.offline {
+		runner.Env = scaffold.OfflineEnv
 	}
 
-	return nil
+	_, err = scaffold.Generate(ctx, scaffold.Options{
+		Template: template,
+		Params:   params,
+		Runner:   runner,
+		Offline:  opts.offline,
+		Vendor:   opts.vendor,
+		Version:  smallGoVersion(),
+	})
+	return err
 }
 
-// runGoModInit initializes a new Go module
-func runGoModInit(projectName string) error {
-	cmd := exec.Command("go", "mod", "init", projectName)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+// selectedTemplate returns the named template with the features added on top of it
+func selectedTemplate(templateName string, features []string) (templates.Template, error) {
+	template := templates.GetTemplateByName(templateName)
+	if template == nil {
+		return nil, fmt.Errorf("unknown template: %s. Use 'small-go list' to see available templates", templateName)
+	}
+	return templates.WithFeatures(template, features)
 }
 
-// runGoModTidy runs go mod tidy to download dependencies
-func runGoModTidy() error {
-	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+// runGoModTidy runs go mod tidy in dir to download dependencies
+func runGoModTidy(ctx context.Context, dir string) error {
+	return goRunner.Run(ctx, dir, "go", "mod", "tidy")
 }
 
-// generateTemplateFiles generates files using the selected template
-func generateTemplateFiles(projectName string, template templates.Template) error {
-	files := template.GenerateFiles(projectName)
-
-	for filePath, content := range files {
-		if err := writeFile(filePath, content); err != nil {
-			return fmt.Errorf("failed to write %s: %w", filePath, err)
-		}
-	}
-
-	return nil
+// runGoModVendor runs go mod vendor in dir to sync its vendor directory
+func runGoModVendor(ctx context.Context, dir string) error {
+	return goRunner.Run(ctx, dir, "go", "mod", "vendor")
 }
 
 // writeFile writes content to a file
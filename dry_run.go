@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/dawit-go/small-go/lockfile"
+	"github.com/dawit-go/small-go/scaffold"
+	"github.com/dawit-go/small-go/templates"
+	"github.com/dawit-go/small-go/textdiff"
+)
+
+// dryRunOptions selects what a dry run prints besides the file tree
+type dryRunOptions struct {
+	showContent bool
+	// diffDir is an existing directory to compare the generated files with
+	diffDir string
+	// features are added on top of the template
+	features []string
+}
+
+// dryRun renders a project and prints what createProject would write,
+// without touching the disk or running any go command
+func dryRun(w io.Writer, templateName string, params templates.Params, opts dryRunOptions) error {
+	template, err := selectedTemplate(templateName, opts.features)
+	if err != nil {
+		return err
+	}
+
+	// Generating into memory adds go.mod and the lockfile like a real run;
+	// only go mod tidy is left out. The base copies under the lockfile's
+	// directory repeat every file, so they are not shown.
+	files := scaffold.MapWriter{}
+	_, err = scaffold.Generate(context.Background(), scaffold.Options{
+		Template: template,
+		Params:   params,
+		Writer:   files,
+		Version:  smallGoVersion(),
+	})
+	if err != nil {
+		return err
+	}
+	for filePath := range files {
+		if strings.HasPrefix(filePath, lockfile.BaseDir+"/") {
+			delete(files, filePath)
+		}
+	}
+	paths := sortedPaths(files)
+
+	printTree(w, params.ProjectName, paths, files)
+	fmt.Fprintln(w, "go mod tidy adds go.sum and the indirect requirements of go.mod")
+
+	if opts.showContent {
+		for _, filePath := range paths {
+			fmt.Fprintf(w, "\n==> %s <==\n", filePath)
+			fmt.Fprint(w, files[filePath])
+			if !strings.HasSuffix(files[filePath], "\n") {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+
+	if opts.diffDir != "" {
+		fmt.Fprintln(w)
+		changed := 0
+		for _, filePath := range paths {
+			existing, err := os.ReadFile(filepath.Join(opts.diffDir, filePath))
+			nameA := "a/" + filePath
+			if os.IsNotExist(err) {
+				nameA = "/dev/null"
+			} else if err != nil {
+				return err
+			}
+
+			current, generated := string(existing), files[filePath]
+			if filePath == "go.mod" {
+				current, generated = goModModule(current), goModModule(generated)
+			}
+			diff := textdiff.Unified(nameA, "b/"+filePath, current, generated, 3)
+			if diff != "" {
+				fmt.Fprint(w, diff)
+				changed++
+			}
+		}
+
+		extra, err := extraFiles(opts.diffDir, files)
+		if err != nil {
+			return err
+		}
+		for _, filePath := range extra {
+			fmt.Fprintf(w, "Only in %s: %s\n", opts.diffDir, filePath)
+			changed++
+		}
+		if changed == 0 {
+			fmt.Fprintf(w, "No differences from %s\n", opts.diffDir)
+		}
+	}
+
+	return nil
+}
+
+// goModModule returns the module line of go.mod content, the only part of
+// go.mod that go mod tidy leaves as generated
+func goModModule(goMod string) string {
+	modulePath := modfile.ModulePath([]byte(goMod))
+	if modulePath == "" {
+		return goMod
+	}
+	return "module " + modulePath + "\n"
+}
+
+// extraFiles returns the files in dir that the project would not contain,
+// leaving out those the go command writes, the base copies and the .git
+// directory
+func extraFiles(dir string, files map[string]string) ([]string, error) {
+	var extra []string
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == ".git" || rel == "vendor" || rel == lockfile.BaseDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := files[rel]; !ok && rel != "go.sum" {
+			extra = append(extra, rel)
+		}
+		return nil
+	})
+	return extra, err
+}
+
+// printTree prints the paths as a tree with the size of every file
+func printTree(w io.Writer, root string, paths []string, files map[string]string) {
+	fmt.Fprintf(w, "%s/\n", root)
+
+	var total int
+	var previous []string
+	for i, filePath := range paths {
+		parts := strings.Split(filePath, "/")
+
+		// Skip the directories already printed for the previous path
+		common := 0
+		for common < len(parts)-1 && common < len(previous)-1 && parts[common] == previous[common] {
+			common++
+		}
+
+		for depth := common; depth < len(parts); depth++ {
+			var prefix strings.Builder
+			for level := 0; level < depth; level++ {
+				if lastAt(paths[i:], parts, level) {
+					prefix.WriteString("    ")
+				} else {
+					prefix.WriteString("│   ")
+				}
+			}
+			if lastAt(paths[i:], parts, depth) {
+				prefix.WriteString("└── ")
+			} else {
+				prefix.WriteString("├── ")
+			}
+
+			if depth < len(parts)-1 {
+				fmt.Fprintf(w, "%s%s/\n", prefix.String(), parts[depth])
+			} else {
+				fmt.Fprintf(w, "%s%s (%d B)\n", prefix.String(), parts[depth], len(files[filePath]))
+			}
+		}
+
+		total += len(files[filePath])
+		previous = parts
+	}
+
+	fmt.Fprintf(w, "\n%d files, %d bytes\n", len(paths), total)
+}
+
+// lastAt reports whether the entry parts[depth] is the last one in its
+// directory, given the remaining sorted paths starting with the current one
+func lastAt(remaining []string, parts []string, depth int) bool {
+	dir := strings.Join(parts[:depth], "/")
+	for _, other := range remaining[1:] {
+		otherParts := strings.Split(other, "/")
+		if len(otherParts) <= depth || strings.Join(otherParts[:depth], "/") != dir {
+			return true
+		}
+		if otherParts[depth] != parts[depth] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dawit-go/small-go/lockfile"
+	"github.com/dawit-go/small-go/scaffold"
+	"github.com/dawit-go/small-go/templates"
+)
+
+// TestDryRunDiffFreshProject checks that a dry run compared with a project
+// just generated with the same options finds nothing to report
+func TestDryRunDiffFreshProject(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a project")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	t.Chdir(t.TempDir())
+	params := templates.Params{
+		ProjectName: "svc",
+		ModulePath:  "example.com/svc",
+		Vars:        map[string]string{"problem_details": "false"},
+	}
+	err := createProject(context.Background(), "hexagonal", params, createOptions{offline: true})
+	var missing *scaffold.MissingModulesError
+	if errors.As(err, &missing) {
+		t.Skipf("dependencies are not in the module cache: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := dryRun(&out, "hexagonal", params, dryRunOptions{diffDir: "svc"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No differences from svc\n") {
+		t.Errorf("dry run reported differences:\n%s", out.String())
+	}
+	// The tree shows the lockfile but not the directory of the base copies
+	if strings.Contains(out.String(), "── "+path.Dir(lockfile.BaseDir)+"/") {
+		t.Errorf("dry run listed the base copies:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "── "+lockfile.FileName) {
+		t.Errorf("dry run left out the lockfile:\n%s", out.String())
+	}
+}
@@ -0,0 +1,158 @@
+// Package lockfile reads and writes the metadata small-go records in the
+// projects it generates.
+package lockfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the lockfile at the root of a generated project
+const FileName = ".small-go.yaml"
+
+// BaseDir holds a copy of the content small-go generated for every file,
+// which small-go upgrade merges against. The go tool ignores it because its
+// name starts with a dot.
+const BaseDir = ".small-go/base"
+
+// Lockfile records how a project was generated
+type Lockfile struct {
+	// Version is the small-go version that generated the project
+	Version   string            `yaml:"version"`
+	Template  Template          `yaml:"template"`
+	Project   Project           `yaml:"project"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	// Features are the features added on top of the template with --with
+	Features []string `yaml:"features,omitempty"`
+	Entities []Entity `yaml:"entities,omitempty"`
+	// Vendor is set when the project's dependencies are vendored, so that
+	// commands changing them keep the vendor directory in sync
+	Vendor bool `yaml:"vendor,omitempty"`
+	// Files maps each file small-go generated to the hash of its generated content
+	Files map[string]string `yaml:"files"`
+}
+
+// Template identifies the template a project was generated from
+type Template struct {
+	Name string `yaml:"name"`
+	// Source is the directory of templates loaded with --template-dir, or the
+	// repository or archive loaded with --template-source
+	Source string `yaml:"source,omitempty"`
+}
+
+// Project holds the names the project was generated with
+type Project struct {
+	Name   string `yaml:"name"`
+	Module string `yaml:"module,omitempty"`
+}
+
+// Entity records an entity added with small-go add entity
+type Entity struct {
+	Name   string `yaml:"name"`
+	Fields string `yaml:"fields,omitempty"`
+}
+
+// Read reads the lockfile of the project in dir
+func Read(dir string) (*Lockfile, error) {
+	content, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lockfile
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if lock.Files == nil {
+		lock.Files = make(map[string]string)
+	}
+	return &lock, nil
+}
+
+// Write writes the lockfile into the project in dir
+func (l *Lockfile) Write(dir string) error {
+	content, err := l.Encode()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, FileName), content, 0644)
+}
+
+// Encode returns the content of the lockfile file
+func (l *Lockfile) Encode() ([]byte, error) {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	header := "# Generated by small-go. Records how this project was generated; do not edit.\n"
+	return append([]byte(header), content...), nil
+}
+
+// Record stores the hash of a file's generated content
+func (l *Lockfile) Record(path string, content []byte) {
+	if l.Files == nil {
+		l.Files = make(map[string]string)
+	}
+	l.Files[path] = Hash(content)
+}
+
+// Modified reports whether a file recorded in the lockfile was changed since
+// small-go last wrote it. Files that were deleted count as modified.
+func (l *Lockfile) Modified(dir, path string) (bool, error) {
+	recorded, ok := l.Files[path]
+	if !ok {
+		return false, fmt.Errorf("%s was not generated by small-go", path)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return Hash(content) != recorded, nil
+}
+
+// Hash returns the hash recorded for file content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// WriteBase replaces the base copies of the project in dir with files
+func WriteBase(dir string, files map[string]string) error {
+	if err := os.RemoveAll(filepath.Join(dir, BaseDir)); err != nil {
+		return err
+	}
+	return UpdateBase(dir, files)
+}
+
+// UpdateBase adds or replaces the base copies of files
+func UpdateBase(dir string, files map[string]string) error {
+	for path, content := range files {
+		basePath := filepath.Join(dir, BaseDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(basePath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(basePath, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadBase returns the content small-go generated for a file. Projects
+// generated before base copies were kept have none.
+func ReadBase(dir, path string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, BaseDir, filepath.FromSlash(path)))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
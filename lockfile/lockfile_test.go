@@ -0,0 +1,83 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	lock := &Lockfile{
+		Version:   "v1.2.3",
+		Template:  Template{Name: "custom", Source: "/templates/custom#v1"},
+		Project:   Project{Name: "svc", Module: "example.com/svc"},
+		Variables: map[string]string{"docker": "true"},
+		Features:  []string{"redis"},
+		Entities:  []Entity{{Name: "Order", Fields: "total:float64"}},
+		Vendor:    true,
+	}
+	lock.Record("main.go", []byte("package main\n"))
+	if err := lock.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, lock) {
+		t.Errorf("read %+v, want %+v", read, lock)
+	}
+}
+
+func TestModified(t *testing.T) {
+	dir := t.TempDir()
+	lock := &Lockfile{}
+	lock.Record("main.go", []byte("package main\n"))
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(want bool) {
+		t.Helper()
+		if modified, err := lock.Modified(dir, "main.go"); err != nil || modified != want {
+			t.Errorf("Modified = %v, %v, want %v", modified, err, want)
+		}
+	}
+
+	check(true) // deleted
+	write("package main\n")
+	check(false)
+	write("package main // changed\n")
+	check(true)
+
+	if _, err := lock.Modified(dir, "other.go"); err == nil {
+		t.Error("files small-go did not generate should be reported")
+	}
+}
+
+func TestBase(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteBase(dir, map[string]string{"a.go": "a", "internal/b.go": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateBase(dir, map[string]string{"internal/b.go": "b2"}); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := ReadBase(dir, "internal/b.go"); err != nil || content != "b2" {
+		t.Errorf("internal/b.go = %q, %v", content, err)
+	}
+
+	// WriteBase drops the copies of files no longer generated
+	if err := WriteBase(dir, map[string]string{"c.go": "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBase(dir, "a.go"); !os.IsNotExist(err) {
+		t.Errorf("a.go should have been removed: %v", err)
+	}
+}
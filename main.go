@@ -2,24 +2,42 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 
+	"github.com/dawit-go/small-go/source"
 	"github.com/dawit-go/small-go/templates"
 	"github.com/spf13/cobra"
+	"golang.org/x/mod/module"
 )
 
 func main() {
 	var rootCmd = &cobra.Command{
-		Use:   "small-go",
-		Short: "Small-Go: A CLI utility for generating Go project scaffolds with different architecture patterns",
+		Use:     "small-go",
+		Version: smallGoVersion(),
+		Short:   "Small-Go: A CLI utility for generating Go project scaffolds with different architecture patterns",
 		Long: `Small-Go is a CLI utility for generating ready-to-use Go project scaffolds 
 with different architecture patterns including Hexagonal Architecture and Clean Architecture.
 
 It standardizes Go project layouts and encourages separation of concerns between 
 Domain, Application, Ports, and Adapters.`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Sources load first so that local template directories can extend them
+			templateSources, _ := cmd.Flags().GetStringSlice("template-source")
+			if err := loadTemplateSources(templateSources); err != nil {
+				fatal(err)
+			}
+			templateDirs, _ := cmd.Flags().GetStringSlice("template-dir")
+			if err := loadTemplateDirs(templateDirs); err != nil {
+				fatal(err)
+			}
+		},
 	}
 
 	var newCmd = &cobra.Command{
@@ -37,15 +55,60 @@ This will generate a complete project scaffold with:
 		Run: func(cmd *cobra.Command, args []string) {
 			projectName := args[0]
 			templateName, _ := cmd.Flags().GetString("template")
+			modulePath, _ := cmd.Flags().GetString("module")
+
+			params := templates.Params{ProjectName: projectName, ModulePath: modulePath}
+			if err := module.CheckImportPath(params.Module()); err != nil {
+				if modulePath == "" {
+					fatal(fmt.Errorf("project name is not a valid module path, set one with --module: %w", err))
+				}
+				fatal(fmt.Errorf("invalid module path: %w", err))
+			}
+
+			// A single loaded template is used without asking
+			if templateName == "" && len(loadedTemplates) == 1 {
+				templateName = loadedTemplates[0].Name()
+			}
 
 			// If no template specified, show interactive selection
 			if templateName == "" {
 				templateName = selectTemplate()
 			}
 
-			if err := createProject(projectName, templateName); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+			// Check the features before asking for variables
+			features, _ := cmd.Flags().GetStringSlice("with")
+			if _, err := selectedTemplate(templateName, features); err != nil {
+				fatal(err)
+			}
+
+			vars, err := parseVars(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			if err := promptVariables(templateName, vars); err != nil {
+				fatal(err)
+			}
+			params.Vars = vars
+
+			showContent, _ := cmd.Flags().GetBool("show-content")
+			diffDir, _ := cmd.Flags().GetString("diff")
+			if dryRunFlag, _ := cmd.Flags().GetBool("dry-run"); dryRunFlag || showContent || diffDir != "" {
+				opts := dryRunOptions{showContent: showContent, diffDir: diffDir, features: features}
+				if err := dryRun(os.Stdout, templateName, params, opts); err != nil {
+					fatal(err)
+				}
+				return
+			}
+
+			// Ctrl-C cancels the generation, which then removes its partial output
+			opts := createOptions{features: features}
+			opts.offline, _ = cmd.Flags().GetBool("offline")
+			opts.vendor, _ = cmd.Flags().GetBool("vendor")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err = createProject(ctx, templateName, params, opts)
+			stop()
+			if err != nil {
+				fatal(err)
 			}
 			fmt.Printf("✅ Successfully created project: %s\n", projectName)
 			fmt.Printf("📁 Navigate to the project: cd %s\n", projectName)
@@ -65,13 +128,128 @@ This will generate a complete project scaffold with:
 		},
 	}
 
-	// Add template flag
+	var listFeaturesCmd = &cobra.Command{
+		Use:   "features",
+		Short: "List features that can be added to templates with --with",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("Available features:")
+			fmt.Println()
+			for i, feature := range templates.GetAvailableFeatures() {
+				fmt.Printf("  %d. %s: %s\n", i+1, feature.Name(), feature.Description())
+				fmt.Printf("     templates: %s\n", strings.Join(feature.Templates(), ", "))
+				if conflicts := feature.Conflicts(); len(conflicts) > 0 {
+					fmt.Printf("     conflicts with: %s\n", strings.Join(conflicts, ", "))
+				}
+			}
+		},
+	}
+	listCmd.AddCommand(listFeaturesCmd)
+
+	var addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add code to a project generated by small-go",
+	}
+
+	var addEntityCmd = &cobra.Command{
+		Use:   "entity [name]",
+		Short: "Add an entity across all layers of an existing project",
+		Long: `Add an entity across all layers of an existing project.
+This generates, following the project's template:
+- Domain entity with the given fields
+- Repository port and implementation
+- Application service
+- HTTP handler and routes
+- Dependency injection providers
+
+The providers and routes are registered in cmd/server/main.go and the
+router, leaving the rest of those files untouched.`,
+		Example: `  small-go add entity Order --fields "total:float64,status:string"`,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			fields, _ := cmd.Flags().GetString("fields")
+			projectDir, _ := cmd.Flags().GetString("dir")
+			noWire, _ := cmd.Flags().GetBool("no-wire")
+
+			if err := addEntity(projectDir, args[0], fields, !noWire); err != nil {
+				fatal(err)
+			}
+			fmt.Printf("✅ Successfully added entity: %s\n", args[0])
+		},
+	}
+
+	var upgradeCmd = &cobra.Command{
+		Use:   "upgrade",
+		Short: "Merge changes from the current template into an existing project",
+		Long: `Merge changes from the current template into an existing project.
+The output the project was generated with is compared to what the current
+template generates, and the differences are merged into the project's files.
+Where your edits overlap a template change, both versions are written between
+conflict markers for you to resolve.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			projectDir, _ := cmd.Flags().GetString("dir")
+
+			if err := upgradeProject(projectDir); err != nil {
+				fatal(err)
+			}
+		},
+	}
+
+	var verifyCmd = &cobra.Command{
+		Use:   "verify [template...]",
+		Short: "Check that templates generate projects that build, vet and test cleanly",
+		Long: `Check that templates generate projects that build, vet and test cleanly.
+Every template, or the given ones, is generated into a temporary directory
+once for each combination of its variables, once more with an entity
+added if it supports entities, and with the features it supports, keeping
+conflicting features in separate projects. Each project then goes through go build,
+go vet and go test. Dependencies are resolved from the local module cache
+only, so no network access is needed once they are cached.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			keep, _ := cmd.Flags().GetBool("keep")
+
+			if err := verifyTemplates(args, keep); err != nil {
+				fatal(err)
+			}
+			fmt.Println("✅ All templates verified")
+		},
+	}
+
+	// Add entity flags
+	addEntityCmd.Flags().String("fields", "", "Comma-separated entity fields as name:type")
+	addEntityCmd.Flags().String("dir", ".", "Project directory")
+	addEntityCmd.Flags().Bool("no-wire", false, "Only generate the files and print the registrations to add by hand")
+	addCmd.AddCommand(addEntityCmd)
+
+	// Add upgrade flags
+	upgradeCmd.Flags().String("dir", ".", "Project directory")
+
+	// Add verify flags
+	verifyCmd.Flags().Bool("keep", false, "Keep the generated projects for inspection")
+
+	// Add template flags
 	newCmd.Flags().StringP("template", "t", "", "Architecture template to use (hexagonal, clean)")
+	newCmd.Flags().String("module", "", "Go module path of the project (defaults to the project name)")
+	newCmd.Flags().Bool("dry-run", false, "Print the files that would be generated without writing anything")
+	newCmd.Flags().Bool("show-content", false, "Print the content of every file (implies --dry-run)")
+	newCmd.Flags().String("diff", "", "Print a unified diff against an existing project directory (implies --dry-run)")
+	newCmd.Flags().StringSlice("with", nil, "Features to add on top of the template, e.g. postgres,redis (see 'small-go list features')")
+	newCmd.Flags().Bool("offline", false, "Resolve dependencies from the local module cache without network access")
+	newCmd.Flags().Bool("vendor", false, "Vendor dependencies with go mod vendor and check the project builds from them")
+	newCmd.Flags().StringArray("var", nil, "Template variable as name=value (repeatable); undeclared ones are prompted for")
+	rootCmd.PersistentFlags().StringSlice("template-dir", nil, "Directory of .tmpl files to load as a template (repeatable)")
+	rootCmd.PersistentFlags().StringSlice("template-source", nil, "Git repository (path#ref), .tar.gz or .zip holding a template to load (repeatable)")
 
-	rootCmd.AddCommand(newCmd, listCmd)
+	rootCmd.AddCommand(newCmd, listCmd, addCmd, upgradeCmd, verifyCmd)
 	rootCmd.Execute()
 }
 
+// fatal reports an error and exits
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
 // selectTemplate provides interactive template selection
 func selectTemplate() string {
 	availableTemplates := templates.GetAvailableTemplates()
@@ -85,7 +263,7 @@ func selectTemplate() string {
 
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Print("Enter your choice (1-2): ")
+		fmt.Printf("Enter your choice (1-%d): ", len(availableTemplates))
 		choice, _ := reader.ReadString('\n')
 		choice = strings.TrimSpace(choice)
 
@@ -100,3 +278,146 @@ func selectTemplate() string {
 		return selectedTemplate.Name()
 	}
 }
+
+// parseVars collects the --var flags into a map of raw values
+func parseVars(cmd *cobra.Command) (map[string]string, error) {
+	pairs, _ := cmd.Flags().GetStringArray("var")
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q, expected name=value", pair)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// promptVariables asks for each variable of the template that was not set with --var
+func promptVariables(templateName string, vars map[string]string) error {
+	template := templates.GetTemplateByName(templateName)
+	if template == nil {
+		// createProject reports unknown templates
+		return nil
+	}
+
+	// Check the values given with --var before asking for the rest
+	variables := templates.GetVariables(template)
+	for name, value := range vars {
+		index := slices.IndexFunc(variables, func(v templates.Variable) bool { return v.Name == name })
+		if index < 0 {
+			return fmt.Errorf("template %s has no variable %s", template.Name(), name)
+		}
+		if _, err := variables[index].Parse(value); err != nil {
+			return err
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, variable := range variables {
+		if _, ok := vars[variable.Name]; ok {
+			continue
+		}
+
+		for {
+			fmt.Print(variablePrompt(variable))
+			answer, err := reader.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				answer = variable.Default
+			}
+			if variable.Type == templates.VarEnum {
+				if index, convErr := strconv.Atoi(answer); convErr == nil && index >= 1 && index <= len(variable.Options) {
+					answer = variable.Options[index-1]
+				}
+			}
+
+			if _, parseErr := variable.Parse(answer); parseErr != nil {
+				if err != nil {
+					return fmt.Errorf("no valid value for variable %s: %w", variable.Name, parseErr)
+				}
+				fmt.Println(parseErr)
+				continue
+			}
+			vars[variable.Name] = answer
+			break
+		}
+	}
+
+	return nil
+}
+
+// variablePrompt formats the question asked for a variable
+func variablePrompt(variable templates.Variable) string {
+	var prompt strings.Builder
+	switch variable.Type {
+	case templates.VarEnum:
+		fmt.Fprintf(&prompt, "%s:\n", variable.Label())
+		for i, option := range variable.Options {
+			fmt.Fprintf(&prompt, "  %d. %s\n", i+1, option)
+		}
+		prompt.WriteString("Enter your choice")
+	case templates.VarBool:
+		fmt.Fprintf(&prompt, "%s (true/false)", variable.Label())
+	case templates.VarInt:
+		fmt.Fprintf(&prompt, "%s (number)", variable.Label())
+	default:
+		prompt.WriteString(variable.Label())
+	}
+	if variable.Default != "" {
+		fmt.Fprintf(&prompt, " [%s]", variable.Default)
+	}
+	prompt.WriteString(": ")
+	return prompt.String()
+}
+
+// loadedTemplates holds the templates loaded from --template-source and --template-dir
+var loadedTemplates []templates.Template
+
+// loadTemplateDirs loads each template directory and registers it next to the built-in templates
+func loadTemplateDirs(dirs []string) error {
+	for _, dir := range dirs {
+		template, err := templates.LoadDirTemplate(dir)
+		if err != nil {
+			return err
+		}
+		if err := templates.Register(template); err != nil {
+			return err
+		}
+		loadedTemplates = append(loadedTemplates, template)
+	}
+	return nil
+}
+
+// loadTemplateSources unpacks each template source and registers it next to the built-in templates
+func loadTemplateSources(specs []string) error {
+	for _, spec := range specs {
+		template, err := loadTemplateSource(spec)
+		if err != nil {
+			return err
+		}
+		if err := templates.Register(template); err != nil {
+			return err
+		}
+		loadedTemplates = append(loadedTemplates, template)
+	}
+	return nil
+}
+
+// loadTemplateSource unpacks a git repository or archive into the cache and
+// loads the template it holds
+func loadTemplateSource(spec string) (*templates.DirTemplate, error) {
+	src, err := source.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := source.CacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot find a cache directory for template sources: %w", err)
+	}
+	dir, err := src.Fetch(context.Background(), cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return templates.LoadFSTemplate(src.Name(), src.String(), os.DirFS(dir))
+}
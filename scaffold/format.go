@@ -0,0 +1,76 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"strings"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/dawit-go/small-go/templates"
+	"github.com/dawit-go/small-go/wiring"
+)
+
+// Render renders the files of a template the way Generate writes them,
+// with every Go file formatted
+func Render(template templates.Template, params templates.Params) (map[string]string, error) {
+	files, err := templates.RenderFiles(template, params)
+	if err != nil {
+		return nil, err
+	}
+	for filePath, content := range files {
+		if !strings.HasSuffix(filePath, ".go") {
+			continue
+		}
+		formatted, err := FormatSource(filePath, []byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("template %s generates invalid Go: %w", template.Name(), err)
+		}
+		files[filePath] = string(formatted)
+	}
+	return files, nil
+}
+
+// RenderEntity renders the files of an entity the way they are written,
+// with every Go file formatted, and the edits that register it
+func RenderEntity(generator templates.EntityGenerator, modulePath string, entity templates.Entity) (map[string]string, []wiring.Edit, error) {
+	files, edits := generator.GenerateEntity(modulePath, entity)
+	for filePath, content := range files {
+		if !strings.HasSuffix(filePath, ".go") {
+			continue
+		}
+		formatted, err := FormatSource(filePath, []byte(content))
+		if err != nil {
+			return nil, nil, err
+		}
+		files[filePath] = string(formatted)
+	}
+	return files, edits, nil
+}
+
+// FormatSource formats Go source like gofmt and sorts its imports into
+// standard library and other groups like goimports, without adding or
+// removing any. Syntax errors name the offending line.
+func FormatSource(filePath string, src []byte) ([]byte, error) {
+	formatted, err := imports.Process(filePath, src, &imports.Options{
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+		FormatOnly: true,
+	})
+	if err == nil {
+		return formatted, nil
+	}
+
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return nil, err
+	}
+	first := list[0]
+	lines := strings.Split(string(src), "\n")
+	if first.Pos.Line < 1 || first.Pos.Line > len(lines) {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s:%d:%d: %s\n%6d | %s", filePath, first.Pos.Line, first.Pos.Column, first.Msg, first.Pos.Line, lines[first.Pos.Line-1])
+}
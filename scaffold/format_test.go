@@ -0,0 +1,39 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dawit-go/small-go/templates"
+)
+
+func TestFormatSource(t *testing.T) {
+	src := "package app\n\nimport (\n\"strings\"\n\"example.com/svc/internal/domain\"\n\"fmt\"\n)\n\nvar  _ = fmt.Sprint\n"
+	got, err := FormatSource("internal/app/app.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package app\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n\n\t\"example.com/svc/internal/domain\"\n)\n\nvar _ = fmt.Sprint\n"
+	if string(got) != want {
+		t.Errorf("formatted:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSourceReportsLine(t *testing.T) {
+	src := "package app\n\nfunc New() *App {\n\treturn &App{name: }\n}\n"
+	_, err := FormatSource("internal/app/app.go", []byte(src))
+	if err == nil {
+		t.Fatal("formatting invalid Go should fail")
+	}
+	for _, want := range []string{"internal/app/app.go:4:", "     4 | \treturn &App{name: }"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	}
+
+	// Render names the template generating the invalid file
+	_, err = Render(testTemplate{"internal/app/app.go": src}, templates.Params{ProjectName: "svc"})
+	if err == nil || !strings.Contains(err.Error(), "template test generates invalid Go: internal/app/app.go:4:") {
+		t.Errorf("rendering invalid Go: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package scaffold
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Writer receives the files of a generated project. Names are slash
+// separated paths relative to the project root, as in io/fs.
+type Writer interface {
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// DirWriter writes files below a directory, creating parent directories as needed
+type DirWriter string
+
+func (d DirWriter) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	filePath := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, perm)
+}
+
+// MapWriter collects the files of a project in memory, keyed by path
+type MapWriter map[string]string
+
+func (m MapWriter) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	m[name] = string(data)
+	return nil
+}
+
+// Runner runs an external command in dir
+type Runner interface {
+	Run(ctx context.Context, dir, name string, args ...string) error
+}
+
+// RunnerFunc adapts a function to the Runner interface
+type RunnerFunc func(ctx context.Context, dir, name string, args ...string) error
+
+func (f RunnerFunc) Run(ctx context.Context, dir, name string, args ...string) error {
+	return f(ctx, dir, name, args...)
+}
+
+// ExecRunner runs commands with os/exec, stopping them when ctx is
+// cancelled. Output goes to Stdout and Stderr; when they are nil it is
+// captured and included in the error of a failing command.
+type ExecRunner struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Env is added to the environment of the process
+	Env []string
+}
+
+func (r ExecRunner) Run(ctx context.Context, dir, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	if len(r.Env) > 0 {
+		cmd.Env = append(os.Environ(), r.Env...)
+	}
+
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = r.Stdout, r.Stderr
+	if cmd.Stdout == nil {
+		cmd.Stdout = &output
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = &output
+	}
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if output.Len() > 0 {
+			return fmt.Errorf("%w\n%s", err, strings.TrimRight(output.String(), "\n"))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/dawit-go/small-go/templates"
+)
+
+// OfflineEnv makes the go command resolve modules from the local module cache only
+var OfflineEnv = []string{"GOFLAGS=-mod=mod", "GOPROXY=off"}
+
+// MissingModulesError lists the dependencies an offline generation cannot
+// find in the module cache
+type MissingModulesError struct {
+	Modules []string
+}
+
+func (e *MissingModulesError) Error() string {
+	return fmt.Sprintf("not in the module cache, run once without --offline to download them:\n  %s", strings.Join(e.Modules, "\n  "))
+}
+
+// moduleCache is a local module cache directory
+type moduleCache string
+
+// defaultModuleCache returns the module cache the go command uses by default
+func defaultModuleCache() moduleCache {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return moduleCache(dir)
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		gopath = filepath.Join(home, "go")
+	}
+	return moduleCache(filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod"))
+}
+
+// versionDir returns the directory holding the cached versions of a module
+func (c moduleCache) versionDir(modulePath string) string {
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(string(c), "cache", "download", filepath.FromSlash(escaped), "@v")
+}
+
+// has reports whether the source of a module version is cached
+func (c moduleCache) has(modulePath, version string) bool {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(c.versionDir(modulePath), escaped+".zip"))
+	return err == nil
+}
+
+// latest returns the module providing a package path, trying the path itself
+// and then each of its parents, and the newest version of it in the cache
+func (c moduleCache) latest(packagePath string) (string, string) {
+	for modulePath := packagePath; ; {
+		if version := c.newest(modulePath); version != "" {
+			return modulePath, version
+		}
+		i := strings.LastIndex(modulePath, "/")
+		if i < 0 {
+			return "", ""
+		}
+		modulePath = modulePath[:i]
+	}
+}
+
+// newest returns the newest cached version of a module, preferring
+// releases over pre-releases
+func (c moduleCache) newest(modulePath string) string {
+	entries, err := os.ReadDir(c.versionDir(modulePath))
+	if err != nil {
+		return ""
+	}
+
+	var release, prerelease string
+	for _, entry := range entries {
+		version, ok := strings.CutSuffix(entry.Name(), ".zip")
+		if !ok || !semver.IsValid(version) {
+			continue
+		}
+		if semver.Prerelease(version) == "" {
+			if release == "" || semver.Compare(version, release) > 0 {
+				release = version
+			}
+		} else if prerelease == "" || semver.Compare(version, prerelease) > 0 {
+			prerelease = version
+		}
+	}
+	if release != "" {
+		return release
+	}
+	return prerelease
+}
+
+// requirements returns the module versions to require for the dependencies.
+// Pinned dependencies are required as declared. Offline, unpinned ones take
+// the newest cached version, and every dependency must be in the cache.
+func requirements(dependencies []templates.Dependency, offline bool, cache moduleCache) ([]module.Version, error) {
+	var required []module.Version
+	var missing []string
+	for _, dependency := range dependencies {
+		switch {
+		case dependency.Pinned():
+			if offline && !cache.has(dependency.Path, dependency.Version) {
+				missing = append(missing, dependency.String())
+			}
+			required = append(required, module.Version{Path: dependency.Path, Version: dependency.Version})
+		case offline:
+			modulePath, version := cache.latest(dependency.Path)
+			if version == "" {
+				missing = append(missing, dependency.Path+" (no version cached)")
+				continue
+			}
+			required = append(required, module.Version{Path: modulePath, Version: version})
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, &MissingModulesError{Modules: missing}
+	}
+	return required, nil
+}
+
+// AddRequirements adds a require directive to go.mod content for every
+// pinned dependency whose module it does not require yet, keeping the
+// requirements sorted
+func AddRequirements(goMod []byte, dependencies []templates.Dependency) ([]byte, error) {
+	var required []module.Version
+	for _, dependency := range dependencies {
+		if dependency.Pinned() {
+			required = append(required, module.Version{Path: dependency.Path, Version: dependency.Version})
+		}
+	}
+	return addRequirements(goMod, required)
+}
+
+func addRequirements(goMod []byte, required []module.Version) ([]byte, error) {
+	file, err := modfile.Parse("go.mod", goMod, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	for _, require := range file.Require {
+		existing[require.Mod.Path] = true
+	}
+	for _, version := range required {
+		if existing[version.Path] {
+			continue
+		}
+		existing[version.Path] = true
+		if err := file.AddRequire(version.Path, version.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the require block sorted whatever order the dependencies come in
+	file.SortBlocks()
+	file.Cleanup()
+	return file.Format()
+}
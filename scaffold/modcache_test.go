@@ -0,0 +1,165 @@
+package scaffold
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dawit-go/small-go/templates"
+)
+
+// fakeModuleCache returns a module cache holding the source of the given
+// "path@version" modules
+func fakeModuleCache(t *testing.T, modules ...string) moduleCache {
+	t.Helper()
+	cache := moduleCache(t.TempDir())
+	for _, m := range modules {
+		modulePath, version, _ := strings.Cut(m, "@")
+		dir := cache.versionDir(modulePath)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		// go mod download also writes .info and .mod files, which are not
+		// enough to build
+		for _, ext := range []string{".info", ".mod", ".zip"} {
+			if err := os.WriteFile(filepath.Join(dir, version+ext), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return cache
+}
+
+func TestModuleCacheNewest(t *testing.T) {
+	cache := fakeModuleCache(t,
+		"example.com/released@v1.2.0",
+		"example.com/released@v1.10.0",
+		"example.com/released@v1.11.0-rc.1",
+		"example.com/released@v0.0.0-20250101000000-abcdefabcdef",
+		"example.com/pseudo@v0.0.0-20230101000000-abcdefabcdef",
+		"example.com/pseudo@v0.0.0-20240101000000-123456123456",
+		"example.com/pseudo@v0.0.0-20220101000000-fedcbafedcba",
+		"example.com/prerelease@v2.0.0-beta.1",
+		"example.com/prerelease@v2.0.0-beta.2",
+		// Module paths with upper-case letters are escaped in the cache
+		"github.com/BurntSushi/toml@v1.3.2",
+	)
+
+	for _, tc := range []struct {
+		modulePath, want string
+	}{
+		{"example.com/released", "v1.10.0"},
+		{"example.com/pseudo", "v0.0.0-20240101000000-123456123456"},
+		{"example.com/prerelease", "v2.0.0-beta.2"},
+		{"github.com/BurntSushi/toml", "v1.3.2"},
+		{"example.com/missing", ""},
+	} {
+		if got := cache.newest(tc.modulePath); got != tc.want {
+			t.Errorf("newest(%s) = %q, want %q", tc.modulePath, got, tc.want)
+		}
+	}
+
+	// A package path resolves to the module providing it
+	if modulePath, version := cache.latest("example.com/released/sub/pkg"); modulePath != "example.com/released" || version != "v1.10.0" {
+		t.Errorf("latest = %s %s, want example.com/released v1.10.0", modulePath, version)
+	}
+	if modulePath, version := cache.latest("example.com/missing/pkg"); modulePath != "" || version != "" {
+		t.Errorf("latest of an uncached package = %s %s", modulePath, version)
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	cache := fakeModuleCache(t,
+		"example.com/pinned@v1.0.0",
+		"example.com/pinned@v1.5.0",
+		"example.com/latest@v0.3.0",
+		"example.com/latest@v0.4.1",
+	)
+	pinned := templates.Dependency{Path: "example.com/pinned", Version: "v1.0.0"}
+	latest := templates.Dependency{Path: "example.com/latest/pkg"}
+
+	for _, tc := range []struct {
+		name         string
+		dependencies []templates.Dependency
+		offline      bool
+		want         string
+		missing      []string
+	}{
+		{
+			name:         "online requires the pinned dependencies only",
+			dependencies: []templates.Dependency{pinned, latest, {Path: "example.com/uncached"}},
+			want:         "example.com/pinned@v1.0.0",
+		},
+		{
+			name:         "offline keeps the pinned version and takes the newest cached one otherwise",
+			dependencies: []templates.Dependency{pinned, latest},
+			offline:      true,
+			want:         "example.com/pinned@v1.0.0 example.com/latest@v0.4.1",
+		},
+		{
+			name: "offline reports every missing module",
+			dependencies: []templates.Dependency{
+				pinned,
+				{Path: "example.com/pinned", Version: "v1.2.0"},
+				{Path: "example.com/uncached/pkg"},
+			},
+			offline: true,
+			missing: []string{"example.com/pinned@v1.2.0", "example.com/uncached/pkg (no version cached)"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			required, err := requirements(tc.dependencies, tc.offline, cache)
+			if tc.missing != nil {
+				var missingErr *MissingModulesError
+				if !errors.As(err, &missingErr) || strings.Join(missingErr.Modules, ",") != strings.Join(tc.missing, ",") {
+					t.Fatalf("error = %v, want the missing modules %v", err, tc.missing)
+				}
+				want := "not in the module cache, run once without --offline to download them:\n  " + strings.Join(tc.missing, "\n  ")
+				if err.Error() != want {
+					t.Errorf("error message:\n%s\nwant:\n%s", err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, version := range required {
+				got = append(got, version.String())
+			}
+			if strings.Join(got, " ") != tc.want {
+				t.Errorf("required = %v, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddRequirements(t *testing.T) {
+	goMod := "module example.com/svc\n\ngo 1.24\n\nrequire example.com/existing v1.0.0\n"
+	got, err := AddRequirements([]byte(goMod), []templates.Dependency{
+		{Path: "example.com/zebra", Version: "v1.0.0"},
+		{Path: "example.com/unpinned"},
+		{Path: "example.com/existing", Version: "v2.0.0"},
+		{Path: "example.com/alpha", Version: "v0.1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `module example.com/svc
+
+go 1.24
+
+require (
+	example.com/alpha v0.1.0
+	example.com/existing v1.0.0
+	example.com/zebra v1.0.0
+)
+`
+	if string(got) != want {
+		t.Errorf("go.mod:\n%s\nwant:\n%s", got, want)
+	}
+}
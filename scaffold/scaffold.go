@@ -0,0 +1,317 @@
+// Package scaffold generates Go projects from small-go templates.
+//
+// Generate never changes the working directory or any other process state:
+// files go to a destination directory or to a caller supplied Writer, and go
+// commands are run through a Runner that callers can replace.
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/dawit-go/small-go/lockfile"
+	"github.com/dawit-go/small-go/templates"
+)
+
+// Options configures a call to Generate
+type Options struct {
+	// Template is the template to generate the project from
+	Template templates.Template
+	// Params are the project name, module path and variables
+	Params templates.Params
+	// Dir is the directory the project is created in. It must not exist
+	// or be empty, and defaults to the project name.
+	Dir string
+	// Writer receives the files instead of Dir when set. Since go commands
+	// need a directory, go.mod is then written directly and dependencies
+	// are left for the caller to resolve.
+	Writer Writer
+	// Runner runs the go commands; ExecRunner is used when it is nil. A
+	// Runner used with Offline must add OfflineEnv to their environment.
+	Runner Runner
+	// Offline resolves every dependency from the local module cache and
+	// fails with a *MissingModulesError listing those that are not cached.
+	// Dependencies the template does not pin use the newest cached version.
+	Offline bool
+	// ModuleCache is the module cache checked when Offline is set; it
+	// defaults to the one the go command uses
+	ModuleCache string
+	// Vendor runs go mod vendor after go mod tidy and checks that the
+	// project builds with -mod=vendor. It needs Dir, not a Writer.
+	Vendor bool
+	// Version is the small-go version recorded in the lockfile
+	Version string
+}
+
+// Result describes a generated project
+type Result struct {
+	// Dir is the project directory, empty when a Writer was used
+	Dir string
+	// Files maps the path of every generated file to its content
+	Files map[string]string
+	// Lockfile records how the project was generated
+	Lockfile *lockfile.Lockfile
+}
+
+// Paths returns the paths of the generated files in sorted order
+func (r *Result) Paths() []string {
+	paths := make([]string, 0, len(r.Files))
+	for filePath := range r.Files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Generate renders the template and writes the project. When writing to a
+// directory, the project is generated in a temporary directory next to it
+// and only moved into place once every step succeeded, so a failure or a
+// cancelled ctx leaves nothing behind.
+func Generate(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Template == nil {
+		return nil, errors.New("no template given")
+	}
+	if err := module.CheckImportPath(opts.Params.Module()); err != nil {
+		return nil, fmt.Errorf("invalid module path: %w", err)
+	}
+	if opts.Vendor && opts.Writer != nil {
+		return nil, errors.New("vendoring runs go commands and needs a directory, not a Writer")
+	}
+
+	dependencies, err := templates.GetDependencies(opts.Template, opts.Params)
+	if err != nil {
+		return nil, err
+	}
+	cache := moduleCache(opts.ModuleCache)
+	if cache == "" {
+		cache = defaultModuleCache()
+	}
+	required, err := requirements(dependencies, opts.Offline, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := Render(opts.Template, opts.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate files: %w", err)
+	}
+	result := &Result{
+		Files:    files,
+		Lockfile: newLockfile(opts, files),
+	}
+
+	if opts.Writer != nil {
+		goMod, err := goModFile(opts.Params.Module())
+		if err != nil {
+			return nil, err
+		}
+		if goMod, err = addRequirements(goMod, required); err != nil {
+			return nil, err
+		}
+		if err := opts.Writer.WriteFile("go.mod", goMod, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write go.mod: %w", err)
+		}
+		if err := writeProject(opts.Writer, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result.Dir = opts.Dir
+	if result.Dir == "" {
+		result.Dir = opts.Params.ProjectName
+	}
+	if err := generateDir(ctx, opts, required, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// generateDir writes the project into a staging directory and renames it
+// to result.Dir once go mod init, go mod tidy and vendoring succeeded
+func generateDir(ctx context.Context, opts Options, required []module.Version, result *Result) error {
+	runner := opts.Runner
+	if runner == nil {
+		runner = ExecRunner{}
+		if opts.Offline {
+			runner = ExecRunner{Env: OfflineEnv}
+		}
+	}
+
+	// Never mix generated files into an existing project
+	if _, err := checkDestination(result.Dir); err != nil {
+		return err
+	}
+
+	// Create the staging directory on the same file system as the
+	// destination so that it can be renamed into place
+	parent := filepath.Dir(filepath.Clean(result.Dir))
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+	stagingDir, err := os.MkdirTemp(parent, "."+filepath.Base(result.Dir)+".small-go-")
+	if err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	if err := runner.Run(ctx, stagingDir, "go", "mod", "init", opts.Params.Module()); err != nil {
+		return fmt.Errorf("failed to initialize Go module: %w", err)
+	}
+	if err := requireModules(stagingDir, required); err != nil {
+		return fmt.Errorf("failed to pin dependencies: %w", err)
+	}
+	if err := writeProject(DirWriter(stagingDir), result); err != nil {
+		return err
+	}
+	if err := runner.Run(ctx, stagingDir, "go", "mod", "tidy"); err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
+	if opts.Vendor {
+		if err := runner.Run(ctx, stagingDir, "go", "mod", "vendor"); err != nil {
+			return fmt.Errorf("failed to run go mod vendor: %w", err)
+		}
+		if err := runner.Run(ctx, stagingDir, "go", "build", "-mod=vendor", "./..."); err != nil {
+			return fmt.Errorf("project does not build from its vendor directory: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Move the finished project into place; an empty directory left at the
+	// destination is replaced
+	if err := os.Chmod(stagingDir, 0755); err != nil {
+		return err
+	}
+	exists, err := checkDestination(result.Dir)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := os.Remove(result.Dir); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", result.Dir, err)
+		}
+	}
+	if err := os.Rename(stagingDir, result.Dir); err != nil {
+		return fmt.Errorf("failed to move project into place: %w", err)
+	}
+	return nil
+}
+
+// checkDestination reports whether dir exists, and refuses it unless it is
+// an empty directory the project can replace
+func checkDestination(dir string) (bool, error) {
+	info, err := os.Lstat(dir)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, fmt.Errorf("%s already exists and is not a directory", dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) > 0 {
+		return false, fmt.Errorf("%s already exists and is not empty", dir)
+	}
+	return true, nil
+}
+
+// writeProject writes the generated files, the lockfile and the base copies
+// small-go upgrade merges against
+func writeProject(w Writer, result *Result) error {
+	for _, filePath := range result.Paths() {
+		if err := w.WriteFile(filePath, []byte(result.Files[filePath]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+	}
+
+	lock, err := result.Lockfile.Encode()
+	if err != nil {
+		return err
+	}
+	if err := w.WriteFile(lockfile.FileName, lock, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockfile.FileName, err)
+	}
+
+	for _, filePath := range result.Paths() {
+		if err := w.WriteFile(path.Join(lockfile.BaseDir, filePath), []byte(result.Files[filePath]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", lockfile.BaseDir, err)
+		}
+	}
+	return nil
+}
+
+// newLockfile records the template, inputs and generated files of a project
+func newLockfile(opts Options, files map[string]string) *lockfile.Lockfile {
+	lock := &lockfile.Lockfile{
+		Version:   opts.Version,
+		Template:  lockfile.Template{Name: opts.Template.Name()},
+		Project:   lockfile.Project{Name: opts.Params.ProjectName, Module: opts.Params.Module()},
+		Variables: opts.Params.Vars,
+		Vendor:    opts.Vendor,
+	}
+	template := opts.Template
+	if composed, ok := template.(*templates.FeatureTemplate); ok {
+		lock.Features = composed.Features()
+		template = composed.Base()
+	}
+	if dirTemplate, ok := template.(*templates.DirTemplate); ok {
+		lock.Template.Source = dirTemplate.Source()
+	}
+	for filePath, content := range files {
+		lock.Record(filePath, []byte(content))
+	}
+	return lock
+}
+
+// requireModules adds the required module versions to the go.mod in dir
+func requireModules(dir string, required []module.Version) error {
+	if len(required) == 0 {
+		return nil
+	}
+	goModPath := filepath.Join(dir, "go.mod")
+	goMod, err := os.ReadFile(goModPath)
+	if err != nil {
+		return err
+	}
+	if goMod, err = addRequirements(goMod, required); err != nil {
+		return err
+	}
+	return os.WriteFile(goModPath, goMod, 0644)
+}
+
+// goVersion matches release versions of the go toolchain
+var goVersion = regexp.MustCompile(`^1\.\d+(\.\d+)?$`)
+
+// goModFile returns what go mod init writes for modulePath, declaring the
+// version of the go toolchain small-go was built with
+func goModFile(modulePath string) ([]byte, error) {
+	file := &modfile.File{}
+	if err := file.AddModuleStmt(modulePath); err != nil {
+		return nil, err
+	}
+	if version := strings.TrimPrefix(runtime.Version(), "go"); goVersion.MatchString(version) {
+		if err := file.AddGoStmt(version); err != nil {
+			return nil, err
+		}
+	}
+	return modfile.Format(file.Syntax), nil
+}
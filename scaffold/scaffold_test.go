@@ -0,0 +1,233 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dawit-go/small-go/lockfile"
+	"github.com/dawit-go/small-go/templates"
+)
+
+// testTemplate generates a fixed set of files without dependencies
+type testTemplate map[string]string
+
+func (t testTemplate) Name() string        { return "test" }
+func (t testTemplate) Description() string { return "Test template" }
+func (t testTemplate) GetDependencies() []string {
+	return nil
+}
+
+func (t testTemplate) GenerateFiles(string) map[string]string {
+	files := make(map[string]string, len(t))
+	for filePath, content := range t {
+		files[filePath] = content
+	}
+	return files
+}
+
+// fakeRunner records the go commands it is asked to run and writes the
+// go.mod of go mod init, without running the go command
+func fakeRunner(commands *[]string) RunnerFunc {
+	return func(ctx context.Context, dir, name string, args ...string) error {
+		*commands = append(*commands, name+" "+strings.Join(args, " "))
+		if len(args) == 3 && args[0] == "mod" && args[1] == "init" {
+			return os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+args[2]+"\n"), 0644)
+		}
+		return nil
+	}
+}
+
+func generateInto(t *testing.T, dir string, runner Runner) (*Result, error) {
+	t.Helper()
+	return Generate(context.Background(), Options{
+		Template: testTemplate{"main.go": "package main\n\nfunc main() {}\n"},
+		Params:   templates.Params{ProjectName: "example.com/svc"},
+		Dir:      dir,
+		Runner:   runner,
+	})
+}
+
+func TestGenerateRefusesFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "svc")
+	if err := os.WriteFile(dir, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var commands []string
+	if _, err := generateInto(t, dir, fakeRunner(&commands)); err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Fatalf("generating over a file: %v", err)
+	}
+	if content, err := os.ReadFile(dir); err != nil || string(content) != "keep me" {
+		t.Errorf("the existing file was changed: %q, %v", content, err)
+	}
+	if len(commands) > 0 {
+		t.Errorf("ran %v before refusing the destination", commands)
+	}
+}
+
+func TestGenerateRefusesNonEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(existing, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var commands []string
+	if _, err := generateInto(t, dir, fakeRunner(&commands)); err == nil || !strings.Contains(err.Error(), "is not empty") {
+		t.Fatalf("generating into a non-empty directory: %v", err)
+	}
+	if content, err := os.ReadFile(existing); err != nil || string(content) != "keep me" {
+		t.Errorf("the existing file was changed: %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err == nil {
+		t.Error("generated files were mixed into the existing directory")
+	}
+}
+
+func TestGenerateStagesProject(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "svc")
+	// An empty directory at the destination is replaced
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var commands, dirs []string
+	record := fakeRunner(&commands)
+	runner := RunnerFunc(func(ctx context.Context, runDir, name string, args ...string) error {
+		dirs = append(dirs, runDir)
+		// The destination stays empty until every command succeeded
+		if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+			t.Errorf("%s has files while %s %v runs", dir, name, args)
+		}
+		return record(ctx, runDir, name, args...)
+	})
+
+	result, err := generateInto(t, dir, runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go mod init example.com/svc", "go mod tidy"}; strings.Join(commands, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+	for _, runDir := range dirs {
+		if filepath.Dir(runDir) != parent || runDir == dir {
+			t.Errorf("command ran in %s, want a staging directory next to %s", runDir, dir)
+		}
+	}
+
+	for _, name := range []string{"go.mod", "main.go", lockfile.FileName, lockfile.BaseDir + "/main.go"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+	lock, err := lockfile.Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Project.Module != "example.com/svc" || lock.Files["main.go"] != result.Lockfile.Files["main.go"] {
+		t.Errorf("lockfile = %+v", lock)
+	}
+	assertEntries(t, parent, "svc")
+}
+
+func TestGenerateCleansUpOnFailure(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "svc")
+
+	var commands []string
+	record := fakeRunner(&commands)
+	runner := RunnerFunc(func(ctx context.Context, runDir, name string, args ...string) error {
+		if len(args) > 1 && args[1] == "tidy" {
+			return errors.New("network unreachable")
+		}
+		return record(ctx, runDir, name, args...)
+	})
+
+	if _, err := generateInto(t, dir, runner); err == nil || !strings.Contains(err.Error(), "network unreachable") {
+		t.Fatalf("failing go mod tidy: %v", err)
+	}
+	if _, err := os.Lstat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s was created by a failed generation", dir)
+	}
+	assertEntries(t, parent)
+
+	// A cancelled generation leaves nothing behind either
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Generate(ctx, Options{
+		Template: testTemplate{"main.go": "package main\n"},
+		Params:   templates.Params{ProjectName: "svc"},
+		Dir:      dir,
+		Runner:   fakeRunner(&commands),
+	})
+	if err == nil {
+		t.Fatal("a cancelled generation should fail")
+	}
+	assertEntries(t, parent)
+}
+
+func TestGenerateToWriter(t *testing.T) {
+	dir := t.TempDir()
+	var commands []string
+	_, err := Generate(context.Background(), Options{
+		Template: testTemplate{"main.go": "package main\n"},
+		Params:   templates.Params{ProjectName: "svc"},
+		Writer:   DirWriter(dir),
+		Runner:   fakeRunner(&commands),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) > 0 {
+		t.Errorf("ran %v with a Writer", commands)
+	}
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil || !strings.HasPrefix(string(goMod), "module svc\n") {
+		t.Errorf("go.mod = %q, %v", goMod, err)
+	}
+}
+
+func TestGenerateToMapWriter(t *testing.T) {
+	files := MapWriter{}
+	var commands []string
+	_, err := Generate(context.Background(), Options{
+		Template: testTemplate{"main.go": "package main\n"},
+		Params:   templates.Params{ProjectName: "svc"},
+		Writer:   files,
+		Runner:   fakeRunner(&commands),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Everything a generation into a directory writes, except go.sum
+	for _, name := range []string{"go.mod", "main.go", lockfile.FileName, lockfile.BaseDir + "/main.go"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s was not written", name)
+		}
+	}
+	if len(files) != 4 || len(commands) > 0 {
+		t.Errorf("wrote %d files and ran %v", len(files), commands)
+	}
+}
+
+// assertEntries checks that dir holds exactly the named entries, so that
+// no staging directory was left behind
+func assertEntries(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		t.Errorf("%s holds %v, want %v", dir, got, names)
+	}
+}
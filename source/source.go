@@ -0,0 +1,319 @@
+// Package source unpacks templates shared as local git repositories or
+// archives into a cache directory, from where they are loaded like template
+// directories. Only file paths are read, so it works without network access.
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of template sources
+const (
+	Git   = "git"
+	TarGz = "tar.gz"
+	Zip   = "zip"
+)
+
+// Source is a git repository at a ref, or an archive
+type Source struct {
+	Kind string
+	// Path is the absolute path of the repository or archive
+	Path string
+	// Ref is the git ref whose tree holds the template
+	Ref string
+}
+
+// Parse parses "path" or, for git repositories, "path#ref". Archives are
+// recognized by their .tar.gz, .tgz or .zip extension; any other path must be
+// a git repository, whose HEAD is used unless a ref is given.
+func Parse(spec string) (*Source, error) {
+	filePath, ref, hasRef := strings.Cut(spec, "#")
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template source %s: %w", spec, err)
+	}
+
+	s := &Source{Path: absPath, Ref: ref}
+	switch {
+	case strings.HasSuffix(absPath, ".tar.gz") || strings.HasSuffix(absPath, ".tgz"):
+		s.Kind = TarGz
+	case strings.HasSuffix(absPath, ".zip"):
+		s.Kind = Zip
+	default:
+		s.Kind = Git
+		if s.Ref == "" {
+			s.Ref = "HEAD"
+		}
+	}
+	if s.Kind != Git && hasRef {
+		return nil, fmt.Errorf("invalid template source %s: only git repositories have refs", spec)
+	}
+	if hasRef && ref == "" {
+		return nil, fmt.Errorf("invalid template source %s: empty ref", spec)
+	}
+
+	if _, err := os.Stat(absPath); err != nil {
+		return nil, fmt.Errorf("failed to open template source: %w", err)
+	}
+	return s, nil
+}
+
+// IsSource reports whether a template source recorded in a lockfile names a
+// repository or archive rather than a template directory. String always
+// includes the ref of git sources, so that the two can be told apart.
+func IsSource(recorded string) bool {
+	return strings.Contains(recorded, "#") ||
+		strings.HasSuffix(recorded, ".tar.gz") || strings.HasSuffix(recorded, ".tgz") ||
+		strings.HasSuffix(recorded, ".zip")
+}
+
+func (s *Source) String() string {
+	if s.Kind == Git {
+		return s.Path + "#" + s.Ref
+	}
+	return s.Path
+}
+
+// Name returns the name of the repository or archive without extension,
+// which templates without a name in their manifest are known by
+func (s *Source) Name() string {
+	name := filepath.Base(s.Path)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip", ".git"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// CacheDir returns the default directory sources are unpacked into
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "small-go", "templates"), nil
+}
+
+// Fetch unpacks the source into a directory of cacheDir named after its
+// content, unless it was unpacked before, and returns the directory holding
+// the template. An archive whose files all lie in a single directory is
+// unpacked without it.
+func (s *Source) Fetch(ctx context.Context, cacheDir string) (string, error) {
+	var key string
+	var unpack func(dir string) error
+	switch s.Kind {
+	case Git:
+		commit, err := git(ctx, s.Path, "rev-parse", "--verify", "--quiet", s.Ref+"^{commit}")
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+			return "", fmt.Errorf("no commit %s in %s", s.Ref, s.Path)
+		}
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve %s in %s: %w", s.Ref, s.Path, err)
+		}
+		key = "git-" + strings.TrimSpace(string(commit))
+		unpack = func(dir string) error {
+			return s.unpackGit(ctx, strings.TrimSpace(string(commit)), dir)
+		}
+	case TarGz, Zip:
+		sum, err := fileHash(s.Path)
+		if err != nil {
+			return "", err
+		}
+		key = strings.ReplaceAll(s.Kind, ".", "-") + "-" + sum
+		unpack = s.unpackArchive
+	}
+
+	dir := filepath.Join(cacheDir, key)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := unpackInto(dir, unpack); err != nil {
+			return "", fmt.Errorf("failed to unpack %s: %w", s, err)
+		}
+	} else if err != nil {
+		return "", err
+	}
+	return templateRoot(dir)
+}
+
+// unpackInto unpacks into a temporary directory next to dir and renames it
+// into place, so that an interrupted unpack is never mistaken for a cached one
+func unpackInto(dir string, unpack func(dir string) error) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".unpack-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := unpack(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		// Another process unpacked the same content first
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// templateRoot returns dir, or its only entry if that is a directory
+func templateRoot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+func (s *Source) unpackGit(ctx context.Context, commit, dir string) error {
+	cmd := exec.CommandContext(ctx, "git", "-C", s.Path, "archive", "--format=tar", commit)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	extractErr := extractTar(stdout, dir)
+	// Drain the output so that git does not block when extraction failed
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git archive: %w\n%s", err, stderr.String())
+	}
+	return extractErr
+}
+
+func (s *Source) unpackArchive(dir string) error {
+	if s.Kind == Zip {
+		return extractZip(s.Path, dir)
+	}
+
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return extractTar(gz, dir)
+}
+
+// extractTar writes the regular files of a tar stream below dir; links and
+// other special entries are skipped
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := writeEntry(dir, header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip writes the regular files of a zip archive below dir
+func extractZip(archive, dir string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeEntry(dir, file.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntry writes an archive entry below dir, refusing names that would
+// end up outside of it
+func writeEntry(dir, name string, r io.Reader) error {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || filepath.VolumeName(clean) != "" {
+		return fmt.Errorf("archive entry %s points outside of the archive", name)
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(clean))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// git runs a git command in the repository and returns its output
+func git(ctx context.Context, repo string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...)
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
+
+// fileHash returns the hex SHA-256 of a file's content
+func fileHash(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
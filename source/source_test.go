@@ -0,0 +1,147 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command not found")
+	}
+
+	ctx := context.Background()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	commit := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "main.go.tmpl"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "-A")
+		run("commit", "-q", "-m", content)
+	}
+	run("init", "-q")
+	commit("v1")
+	run("tag", "v1")
+	commit("v2")
+
+	cacheDir := t.TempDir()
+	for spec, want := range map[string]string{repo: "v2", repo + "#v1": "v1"} {
+		src, err := Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir, err := src.Fetch(ctx, cacheDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, "main.go.tmpl")); string(got) != want {
+			t.Errorf("%s: main.go.tmpl = %q, want %q", spec, got, want)
+		}
+	}
+
+	src, err := Parse(repo + "#v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Fetch(ctx, cacheDir); err == nil || !strings.Contains(err.Error(), "no commit v3") {
+		t.Errorf("fetching a missing ref: %v", err)
+	}
+}
+
+func TestFetchArchive(t *testing.T) {
+	files := map[string]string{
+		"template-1.0/template.yaml": "name: shared\n",
+		"template-1.0/cmd/main.tmpl": "package main\n",
+	}
+	dir := t.TempDir()
+	tgz := filepath.Join(dir, "template.tar.gz")
+	zipPath := filepath.Join(dir, "template.zip")
+	writeTarGz(t, tgz, files)
+	writeZip(t, zipPath, files)
+
+	cacheDir := t.TempDir()
+	for _, archive := range []string{tgz, zipPath} {
+		src, err := Parse(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src.Name() != "template" {
+			t.Errorf("%s: name = %s", archive, src.Name())
+		}
+		root, err := src.Fetch(context.Background(), cacheDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The single top-level directory is left out
+		if got, _ := os.ReadFile(filepath.Join(root, "cmd", "main.tmpl")); string(got) != "package main\n" {
+			t.Errorf("%s: cmd/main.tmpl = %q", archive, got)
+		}
+	}
+
+	if _, err := Parse(tgz + "#v1"); err == nil {
+		t.Error("archives should not accept a ref")
+	}
+
+	evil := filepath.Join(dir, "evil.tar.gz")
+	writeTarGz(t, evil, map[string]string{"../escaped": "x"})
+	src, err := Parse(evil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Fetch(context.Background(), cacheDir); err == nil || !strings.Contains(err.Error(), "outside of the archive") {
+		t.Errorf("fetching an archive escaping its directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(cacheDir), "escaped")); err == nil {
+		t.Error("archive entry was written outside of the cache")
+	}
+}
+
+func writeTarGz(t *testing.T, archive string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, archive string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
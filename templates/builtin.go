@@ -0,0 +1,49 @@
+package templates
+
+import (
+	"embed"
+	"io/fs"
+	"sync"
+)
+
+// builtinFS holds the files of the built-in templates, one directory per
+// template laid out like a template directory
+//
+//go:embed builtin
+var builtinFS embed.FS
+
+// sourceFS holds the sources of the built-in features and of the entities
+// added to projects of the built-in templates
+//
+//go:embed entities features
+var sourceFS embed.FS
+
+// builtinSource returns a file of sourceFS. The files are part of the
+// binary, so a missing one is a bug.
+func builtinSource(name string) string {
+	content, err := sourceFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(content)
+}
+
+// Built-in templates are loaded from builtinFS the first time they are used
+var (
+	hexagonalFiles = sync.OnceValue(func() *DirTemplate { return loadBuiltin("hexagonal") })
+	cleanFiles     = sync.OnceValue(func() *DirTemplate { return loadBuiltin("clean") })
+)
+
+// loadBuiltin loads the embedded directory of a built-in template. Its files
+// are part of the binary, so failing to load them is a bug.
+func loadBuiltin(name string) *DirTemplate {
+	fsys, err := fs.Sub(builtinFS, "builtin/"+name)
+	if err != nil {
+		panic(err)
+	}
+	template, err := LoadFSTemplate(name, "builtin", fsys)
+	if err != nil {
+		panic(err)
+	}
+	return template
+}
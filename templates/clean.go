@@ -1,6 +1,9 @@
 package templates
 
+import "github.com/dawit-go/small-go/wiring"
+
 // CleanTemplate represents the clean architecture template
+// rendered from the files embedded under builtin/clean
 type CleanTemplate struct{}
 
 func (c *CleanTemplate) Name() string {
@@ -8,39 +11,27 @@ func (c *CleanTemplate) Name() string {
 }
 
 func (c *CleanTemplate) Description() string {
-	return "Clean Architecture with Domain-Driven Design (DDD) principles"
+	return cleanFiles().Description()
 }
 
 func (c *CleanTemplate) GenerateFiles(projectName string) map[string]string {
-	return map[string]string{
-		"cmd/server/main.go":                                    generateCleanMainGo(projectName),
-		"internal/domain/entity/user.go":                        generateCleanDomainEntity(),
-		"internal/domain/service/user_service.go":               generateCleanDomainService(projectName),
-		"internal/storage/interfaces/user_repository.go":        generateCleanStorageInterface(projectName),
-		"internal/storage/mongo/user_repository.go":             generateCleanMongoRepository(projectName),
-		"internal/handler/rest/dto/user_dto.go":                 generateCleanUserDTO(projectName),
-		"internal/handler/rest/http/user_handler.go":            generateCleanUserHandler(projectName),
-		"internal/handler/rest/mapper/user_mapper.go":           generateCleanUserMapper(projectName),
-		"internal/handler/middleware/auth.go":                   generateCleanAuthMiddleware(),
-		"internal/glue/routing/routes.go":                       generateCleanRoutes(projectName),
-		"initiator/initiator.go":                                generateCleanInitiator(projectName),
-		"initiator/service.go":                                  generateCleanServiceInitiator(projectName),
-		"initiator/persistence.go":                              generateCleanPersistenceInitiator(projectName),
-		"initiator/handler.go":                                  generateCleanHandlerInitiator(projectName),
-		"initiator/config.go":                                   generateCleanConfigInitiator(),
-		"initiator/logger.go":                                   generateCleanLoggerInitiator(),
-		"platform/utils/response.go":                            generateCleanResponseUtils(),
-		"platform/mongo/connection.go":                          generateCleanMongoConnection(),
-		"README.md":                                             generateREADME(projectName, "clean"),
-	}
+	return cleanFiles().GenerateFiles(projectName)
+}
+
+// Render generates the files with their imports rooted at the module path
+func (c *CleanTemplate) Render(params Params) (map[string]string, error) {
+	return cleanFiles().Render(params)
+}
+
+// Variables returns the options of the template
+func (c *CleanTemplate) Variables() []Variable {
+	return cleanFiles().Variables()
 }
 
 func (c *CleanTemplate) GetDependencies() []string {
-	return []string{
-		"github.com/go-chi/chi/v5",
-		"go.uber.org/fx",
-		"go.uber.org/zap",
-		"go.mongodb.org/mongo-driver/mongo",
-		"go.mongodb.org/mongo-driver/bson",
-	}
-} 
\ No newline at end of file
+	return cleanFiles().GetDependencies()
+}
+
+func (c *CleanTemplate) GenerateEntity(modulePath string, entity Entity) (map[string]string, []wiring.Edit) {
+	return renderEntityFiles(cleanEntityFiles, modulePath, entity), generateCleanEntityEdits(modulePath, entity)
+}
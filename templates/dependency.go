@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Dependency is a module the generated code imports. Templates declare
+// dependencies as "path@version" to pin them, or as a bare path to take
+// whatever version go mod tidy resolves.
+type Dependency struct {
+	Path    string
+	Version string
+}
+
+// ParseDependency parses a "path" or "path@version" dependency
+func ParseDependency(s string) (Dependency, error) {
+	path, version, pinned := strings.Cut(s, "@")
+	if err := module.CheckImportPath(path); err != nil {
+		return Dependency{}, fmt.Errorf("invalid dependency %q: %w", s, err)
+	}
+	if pinned && !semver.IsValid(version) {
+		return Dependency{}, fmt.Errorf("invalid dependency %q: %s is not a semantic version", s, version)
+	}
+	return Dependency{Path: path, Version: version}, nil
+}
+
+// Pinned reports whether the dependency names a version
+func (d Dependency) Pinned() bool {
+	return d.Version != ""
+}
+
+func (d Dependency) String() string {
+	if d.Pinned() {
+		return d.Path + "@" + d.Version
+	}
+	return d.Path
+}
+
+// GetDependencies parses the dependencies of a template for the project
+// described by params
+func GetDependencies(template Template, params Params) ([]Dependency, error) {
+	declared := template.GetDependencies()
+	if renderer, ok := template.(DependencyRenderer); ok {
+		var err error
+		if declared, err = renderer.RenderDependencies(params); err != nil {
+			return nil, err
+		}
+	}
+
+	var dependencies []Dependency
+	for _, s := range declared {
+		dependency, err := ParseDependency(s)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", template.Name(), err)
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, nil
+}
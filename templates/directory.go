@@ -0,0 +1,298 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templateExt marks the files of a directory template that get rendered
+const templateExt = ".tmpl"
+
+// TemplateData holds the values available to directory templates
+type TemplateData struct {
+	ProjectName string
+	ModulePath  string
+	Vars        map[string]any
+}
+
+// DirTemplate represents a template loaded from a directory of .tmpl files.
+// Each file is rendered with text/template and written to the same relative
+// path without the .tmpl extension. An optional template.yaml manifest
+// describes the template, its variables and conditional files, and may name
+// a template it extends.
+type DirTemplate struct {
+	name     string
+	source   string
+	manifest Manifest
+	files    map[string]*template.Template
+	// parent is the template named by the manifest's extends
+	parent Template
+}
+
+// LoadDirTemplate loads a template from a directory on disk. Unless the
+// manifest names it, the template is named after the directory.
+func LoadDirTemplate(dir string) (*DirTemplate, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve template directory: %w", err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open template directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("template path %s is not a directory", dir)
+	}
+	return LoadFSTemplate(filepath.Base(absDir), absDir, os.DirFS(absDir))
+}
+
+// LoadFSTemplate loads a template from the .tmpl files in fsys.
+// Source describes where the files came from and is only used for messages.
+func LoadFSTemplate(name, source string, fsys fs.FS) (*DirTemplate, error) {
+	t := &DirTemplate{
+		name:   name,
+		source: source,
+		files:  make(map[string]*template.Template),
+	}
+
+	manifest, err := readManifest(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", name, err)
+	}
+	if manifest != nil {
+		t.manifest = *manifest
+		if manifest.Name != "" {
+			t.name = manifest.Name
+		}
+	}
+	if err := t.resolveParent(); err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", t.name, err)
+	}
+
+	err = fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(filePath, templateExt) {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+
+		tmpl, err := template.New(filePath).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		t.files[strings.TrimSuffix(filePath, templateExt)] = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", t.name, err)
+	}
+	if len(t.files) == 0 && t.parent == nil {
+		return nil, fmt.Errorf("template %s has no %s files", t.name, templateExt)
+	}
+
+	// Render once with placeholder values so that references to unknown
+	// fields are reported when the template is loaded, not halfway through
+	// generating a project
+	sample := TemplateData{ProjectName: "example", ModulePath: "example.com/example", Vars: make(map[string]any)}
+	for _, v := range t.Variables() {
+		sample.Vars[v.Name] = v.sample()
+	}
+	if _, err := t.render(sample); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// resolveParent looks up the template named by extends, which must have
+// been loaded before this one
+func (t *DirTemplate) resolveParent() error {
+	if t.manifest.Extends == "" {
+		return nil
+	}
+	t.parent = GetTemplateByName(t.manifest.Extends)
+	if t.parent == nil {
+		return fmt.Errorf("extends unknown template %s; load the templates it extends first", t.manifest.Extends)
+	}
+
+	for _, inherited := range GetVariables(t.parent) {
+		for _, v := range t.manifest.Variables {
+			if v.Name == inherited.Name {
+				return fmt.Errorf("variable %s is already declared by template %s", v.Name, t.parent.Name())
+			}
+		}
+	}
+	return nil
+}
+
+func (t *DirTemplate) Name() string {
+	return t.name
+}
+
+func (t *DirTemplate) Description() string {
+	if t.manifest.Description != "" {
+		return t.manifest.Description
+	}
+	if t.parent != nil {
+		return fmt.Sprintf("Template extending %s, loaded from %s", t.parent.Name(), t.source)
+	}
+	return fmt.Sprintf("Template loaded from %s", t.source)
+}
+
+// GenerateFiles renders every file of the template using variable defaults.
+// Render errors result in an empty map; use RenderFiles to observe them.
+func (t *DirTemplate) GenerateFiles(projectName string) map[string]string {
+	files, err := t.Render(Params{ProjectName: projectName})
+	if err != nil {
+		return map[string]string{}
+	}
+	return files
+}
+
+// GetDependencies returns the dependencies of the extended template followed
+// by those of the manifest, which replace inherited ones of the same module
+func (t *DirTemplate) GetDependencies() []string {
+	if t.parent == nil {
+		return t.manifest.Dependencies
+	}
+
+	dependencies := append([]string{}, t.parent.GetDependencies()...)
+	for _, dependency := range t.manifest.Dependencies {
+		modulePath := strings.Split(dependency, "@")[0]
+		i := slices.IndexFunc(dependencies, func(inherited string) bool {
+			return strings.Split(inherited, "@")[0] == modulePath
+		})
+		if i < 0 {
+			dependencies = append(dependencies, dependency)
+		} else {
+			dependencies[i] = dependency
+		}
+	}
+	return dependencies
+}
+
+// Source returns where the template was loaded from
+func (t *DirTemplate) Source() string {
+	return t.source
+}
+
+// Parent returns the template this one extends, or nil
+func (t *DirTemplate) Parent() Template {
+	return t.parent
+}
+
+// Variables returns the variables of the extended template followed by
+// those declared in the manifest
+func (t *DirTemplate) Variables() []Variable {
+	if t.parent == nil {
+		return t.manifest.Variables
+	}
+	return append(GetVariables(t.parent), t.manifest.Variables...)
+}
+
+// Render validates the variables and renders every file of the template on
+// top of the files it inherits
+func (t *DirTemplate) Render(params Params) (map[string]string, error) {
+	vars, err := ResolveVariables(t.Variables(), params.Vars)
+	if err != nil {
+		return nil, err
+	}
+	data := TemplateData{ProjectName: params.ProjectName, ModulePath: params.Module(), Vars: vars}
+
+	files, err := t.render(data)
+	if err != nil || t.parent == nil {
+		return files, err
+	}
+
+	inherited, err := t.inherit(params, data)
+	if err != nil {
+		return nil, err
+	}
+	for filePath, content := range files {
+		inherited[filePath] = content
+	}
+	return inherited, nil
+}
+
+// inherit renders the extended template without the files the manifest
+// deletes or whose conditions do not hold
+func (t *DirTemplate) inherit(params Params, data TemplateData) (map[string]string, error) {
+	parentParams := Params{ProjectName: params.ProjectName, ModulePath: params.ModulePath, Vars: make(map[string]string)}
+	for _, v := range GetVariables(t.parent) {
+		if value, ok := params.Vars[v.Name]; ok {
+			parentParams.Vars[v.Name] = value
+		}
+	}
+
+	files, err := RenderFiles(t.parent, parentParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s, which %s extends: %w", t.parent.Name(), t.name, err)
+	}
+	for filePath := range files {
+		include, err := t.includes(filePath, data)
+		if err != nil {
+			return nil, err
+		}
+		if !include || t.manifest.Deletes(filePath) {
+			delete(files, filePath)
+		}
+	}
+	return files, nil
+}
+
+// render renders the files whose conditions hold for data
+func (t *DirTemplate) render(data TemplateData) (map[string]string, error) {
+	paths := make([]string, 0, len(t.files))
+	for filePath := range t.files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	files := make(map[string]string, len(paths))
+	for _, filePath := range paths {
+		include, err := t.includes(filePath, data)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := t.files[filePath].Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", filePath+templateExt, err)
+		}
+		files[path.Clean(filePath)] = buf.String()
+	}
+
+	return files, nil
+}
+
+// includes reports whether every manifest rule matching the file holds
+func (t *DirTemplate) includes(filePath string, data TemplateData) (bool, error) {
+	for i := range t.manifest.Files {
+		rule := &t.manifest.Files[i]
+		if !rule.Matches(filePath) {
+			continue
+		}
+		include, err := rule.Include(data)
+		if err != nil || !include {
+			return false, err
+		}
+	}
+	return true, nil
+}
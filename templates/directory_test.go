@@ -0,0 +1,74 @@
+package templates
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExtends(t *testing.T) {
+	template, err := LoadFSTemplate("acme", "memory", fstest.MapFS{
+		"template.yaml": {Data: []byte(`
+extends: hexagonal
+delete:
+  - README.md
+  - internal/ports/
+dependencies:
+  - go.uber.org/zap@v1.26.0
+  - github.com/google/uuid@v1.6.0
+variables:
+  - name: docker
+    type: bool
+files:
+  - path: Dockerfile
+    when: .Vars.docker
+  - path: adapters/
+    when: .Vars.docker
+`)},
+		"Dockerfile.tmpl":        {Data: []byte("FROM golang\n")},
+		"initiators/app.go.tmpl": {Data: []byte("package initiators\n\n// {{.ModulePath}}\n")},
+		"docs/overview.md.tmpl":  {Data: []byte("# {{.ProjectName}}\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent, err := RenderFiles(GetTemplateByName("hexagonal"), Params{ProjectName: "svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := RenderFiles(template, Params{ProjectName: "svc", Vars: map[string]string{"docker": "false"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for filePath, content := range parent {
+		deleted := filePath == "README.md" || strings.HasPrefix(filePath, "internal/ports/") || strings.HasPrefix(filePath, "adapters/")
+		switch got, ok := files[filePath]; {
+		case deleted && ok:
+			t.Errorf("%s should have been deleted", filePath)
+		case !deleted && !ok:
+			t.Errorf("%s should have been inherited", filePath)
+		case ok && filePath != "initiators/app.go" && got != content:
+			t.Errorf("%s should be inherited unchanged", filePath)
+		}
+	}
+	if got := files["initiators/app.go"]; got != "package initiators\n\n// svc\n" {
+		t.Errorf("initiators/app.go was not overridden: %q", got)
+	}
+	if _, ok := files["docs/overview.md"]; !ok {
+		t.Error("docs/overview.md was not added")
+	}
+	if _, ok := files["Dockerfile"]; ok {
+		t.Error("Dockerfile should only be generated with docker=true")
+	}
+
+	want := []string{"github.com/go-chi/chi/v5@v5.2.1", "go.uber.org/fx@v1.24.0", "go.uber.org/zap@v1.26.0", "github.com/go-playground/validator/v10@v10.30.1", "github.com/google/uuid@v1.6.0"}
+	if got := template.GetDependencies(); !slices.Equal(got, want) {
+		t.Errorf("dependencies = %v, want %v", got, want)
+	}
+	if _, ok := GetEntityGenerator(template); !ok {
+		t.Error("entities of the extended template should be supported")
+	}
+}
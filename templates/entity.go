@@ -0,0 +1,338 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/dawit-go/small-go/wiring"
+)
+
+// Entity describes an aggregate added to an existing project
+type Entity struct {
+	Name   string
+	Fields []Field
+}
+
+// Field is a field of an entity
+type Field struct {
+	Name  string
+	JSON  string
+	Type  string
+	Param string
+}
+
+// EntityGenerator is implemented by templates that can add entities to projects they generated
+type EntityGenerator interface {
+	GenerateEntity(modulePath string, entity Entity) (map[string]string, []wiring.Edit)
+}
+
+// GetEntityGenerator returns the generator adding entities to projects of a
+// template: the template itself, or the template it extends or adds
+// features to
+func GetEntityGenerator(template Template) (EntityGenerator, bool) {
+	for template != nil {
+		if generator, ok := template.(EntityGenerator); ok {
+			return generator, true
+		}
+		template = parentOf(template)
+	}
+	return nil, false
+}
+
+// parentOf returns the template a template builds on, or nil
+func parentOf(template Template) Template {
+	switch t := template.(type) {
+	case *DirTemplate:
+		return t.Parent()
+	case *FeatureTemplate:
+		return t.Base()
+	default:
+		return nil
+	}
+}
+
+// fieldTypes lists the types entity fields can have
+var fieldTypes = map[string]bool{
+	"string": true, "bool": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "time.Time": true,
+}
+
+// reservedNames are the variables, receivers and imported packages of the
+// generated entity code, which entity variables and constructor parameters
+// must not shadow
+var reservedNames = map[string]bool{
+	"ctx": true, "err": true, "now": true, "req": true, "fields": true, "id": true,
+	"collection": true, "data": true, "exists": true, "handler": true, "objectID": true,
+	"response": true, "result": true, "service": true, "validator": true,
+	"h": true, "m": true, "r": true, "s": true, "w": true,
+	"bson": true, "context": true, "errors": true, "fmt": true, "http": true, "json": true,
+	"mongo": true, "primitive": true, "slices": true, "strings": true, "sync": true, "time": true,
+	"application": true, "domain": true, "domainerr": true, "dto": true, "entity": true,
+	"inbound": true, "interfaces": true, "mapper": true, "outbound": true, "persistence": true,
+	"utils": true, "mongoplatform": true, "mongorepo": true, "userhandler": true,
+}
+
+// reserved reports whether name cannot be used for a variable of generated
+// code: it is a keyword, a predeclared identifier or one of reservedNames
+func reserved(name string) bool {
+	return token.IsKeyword(name) || types.Universe.Lookup(name) != nil || reservedNames[name]
+}
+
+// ParseEntity parses an entity name and a field list such as "total:float64,status:string"
+func ParseEntity(name, fields string) (Entity, error) {
+	if !token.IsIdentifier(name) {
+		return Entity{}, fmt.Errorf("invalid entity name: %q", name)
+	}
+	entity := Entity{Name: exported(name)}
+
+	// Fields are unique by Go name and by JSON name, e.g. "Id" clashes with "ID"
+	seen := map[string]bool{
+		"ID": true, "CreatedAt": true, "UpdatedAt": true,
+		"id": true, "created_at": true, "updated_at": true,
+	}
+	for _, spec := range strings.Split(fields, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		fieldName, fieldType, ok := strings.Cut(spec, ":")
+		fieldName, fieldType = strings.TrimSpace(fieldName), strings.TrimSpace(fieldType)
+		if !ok || fieldName == "" {
+			return Entity{}, fmt.Errorf("invalid field %q, expected name:type", spec)
+		}
+		if !fieldTypes[strings.TrimPrefix(fieldType, "[]")] {
+			return Entity{}, fmt.Errorf("field %s has unsupported type %q", fieldName, fieldType)
+		}
+
+		field := newField(fieldName, fieldType)
+		if !token.IsIdentifier(field.Name) {
+			return Entity{}, fmt.Errorf("invalid field name: %q", fieldName)
+		}
+		if seen[field.Name] || seen[field.JSON] {
+			return Entity{}, fmt.Errorf("field %s is defined twice or reserved", fieldName)
+		}
+		seen[field.Name], seen[field.JSON] = true, true
+
+		if reserved(field.Param) || field.Param == entity.Var() {
+			field.Param += "Value"
+		}
+		entity.Fields = append(entity.Fields, field)
+	}
+
+	return entity, nil
+}
+
+// newField derives the Go, JSON and parameter names of a field from its spec name
+func newField(name, fieldType string) Field {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	var goName strings.Builder
+	for _, word := range words {
+		goName.WriteString(exported(word))
+	}
+	return Field{
+		Name:  goName.String(),
+		JSON:  snakeCase(goName.String()),
+		Type:  fieldType,
+		Param: unexported(goName.String()),
+	}
+}
+
+// Var returns the entity name as a local variable, e.g. "order", with an
+// Entity suffix when it would be reserved, e.g. "typeEntity"
+func (e Entity) Var() string {
+	name := unexported(e.Name)
+	if reserved(name) {
+		name += "Entity"
+	}
+	return name
+}
+
+// Words returns the entity name as lower-case words for comments and messages, e.g. "order item"
+func (e Entity) Words() string {
+	return strings.ReplaceAll(e.File(), "_", " ")
+}
+
+// Article returns the indefinite article for the entity name
+func (e Entity) Article() string {
+	if strings.ContainsRune("AEIOU", rune(e.Name[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// Plural returns the plural entity name, e.g. "Orders"
+func (e Entity) Plural() string {
+	switch {
+	case len(e.Name) > 1 && strings.HasSuffix(e.Name, "y") && !strings.ContainsRune("aeiou", rune(e.Name[len(e.Name)-2])):
+		return e.Name[:len(e.Name)-1] + "ies"
+	case strings.HasSuffix(e.Name, "s"), strings.HasSuffix(e.Name, "x"),
+		strings.HasSuffix(e.Name, "ch"), strings.HasSuffix(e.Name, "sh"):
+		return e.Name + "es"
+	default:
+		return e.Name + "s"
+	}
+}
+
+// PluralVar returns the plural entity name as a local variable, e.g. "orders"
+func (e Entity) PluralVar() string {
+	return unexported(e.Plural())
+}
+
+// File returns the base name used for the entity's files, e.g. "order_item"
+func (e Entity) File() string {
+	return snakeCase(e.Name)
+}
+
+// Collection returns the plural snake case name used for storage, e.g. "order_items"
+func (e Entity) Collection() string {
+	return snakeCase(e.Plural())
+}
+
+// Route returns the URL path of the entity's resources, e.g. "/order-items"
+func (e Entity) Route() string {
+	return "/" + strings.ReplaceAll(e.Collection(), "_", "-")
+}
+
+// Params returns the constructor parameter list, e.g. "total float64, status string"
+func (e Entity) Params() string {
+	params := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		params[i] = field.Param + " " + field.Type
+	}
+	return strings.Join(params, ", ")
+}
+
+// Args returns the constructor parameters as arguments, prefixed with prefix
+func (e Entity) Args(prefix string) string {
+	args := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		if prefix == "" {
+			args[i] = field.Param
+		} else {
+			args[i] = prefix + field.Name
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// UsesTime reports whether any field needs the time package
+func (e Entity) UsesTime() bool {
+	for _, field := range e.Fields {
+		if strings.HasSuffix(field.Type, "time.Time") {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredFields returns the fields the entity constructor rejects when unset
+func (e Entity) RequiredFields() []Field {
+	var required []Field
+	for _, field := range e.Fields {
+		if field.Required() {
+			required = append(required, field)
+		}
+	}
+	return required
+}
+
+// UsesStrings reports whether the entity constructor needs the strings package
+func (e Entity) UsesStrings() bool {
+	for _, field := range e.Fields {
+		if field.Type == "string" {
+			return true
+		}
+	}
+	return false
+}
+
+// Required reports whether the field must be set: strings must not be blank
+// and times must not be zero, while the zero value of other types is valid
+func (f Field) Required() bool {
+	return f.Type == "string" || f.Type == "time.Time"
+}
+
+// Missing returns the condition under which a required constructor
+// parameter is unset, e.g. strings.TrimSpace(status) == ""
+func (f Field) Missing() string {
+	if f.Type == "time.Time" {
+		return f.Param + ".IsZero()"
+	}
+	return "strings.TrimSpace(" + f.Param + `) == ""`
+}
+
+// entityData holds the values available to entity templates
+type entityData struct {
+	Entity
+	ModulePath string
+}
+
+// renderEntityFiles renders a set of entity sources under entities/ keyed by templated file path
+func renderEntityFiles(sources map[string]string, modulePath string, entity Entity) map[string]string {
+	data := entityData{Entity: entity, ModulePath: modulePath}
+
+	files := make(map[string]string, len(sources))
+	for filePath, source := range sources {
+		files[renderBuiltinTemplate(filePath, data)] = renderBuiltinTemplate(builtinSource("entities/"+source), data)
+	}
+	return files
+}
+
+// renderBuiltinTemplate renders one of the built-in entity or feature
+// templates, which are fixed at compile time and only fail to render because
+// of programming errors
+func renderBuiltinTemplate(source string, data any) string {
+	tmpl := template.Must(template.New("builtin").Parse(source))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+// exported upper-cases the first letter of name
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// unexported lower-cases the leading upper-case letters of name, e.g. "HTTPServer" becomes "httpServer"
+func unexported(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// snakeCase converts a Go name to snake case, e.g. "OrderItem" becomes "order_item"
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package templates
+
+import "github.com/dawit-go/small-go/wiring"
+
+// Hexagonal Architecture Entity Generators
+
+// hexagonalEntityFiles maps the templated path of every file of an entity to
+// its source under entities/
+var hexagonalEntityFiles = map[string]string{
+	"internal/domain/{{.File}}.go":                          "hexagonal/internal/domain/entity.go.tmpl",
+	"internal/application/{{.File}}_service.go":             "hexagonal/internal/application/entity_service.go.tmpl",
+	"internal/ports/inbound/{{.File}}_service.go":           "hexagonal/internal/ports/inbound/entity_service.go.tmpl",
+	"internal/ports/outbound/{{.File}}_repository.go":       "hexagonal/internal/ports/outbound/entity_repository.go.tmpl",
+	"adapters/inbound/http/{{.File}}_handler.go":            "hexagonal/adapters/inbound/http/entity_handler.go.tmpl",
+	"adapters/outbound/persistence/{{.File}}_repository.go": "hexagonal/adapters/outbound/persistence/entity_repository.go.tmpl",
+	"initiators/{{.File}}.go":                               "hexagonal/initiators/entity.go.tmpl",
+}
+
+// entityRoutes returns the source of the chi route block registering an
+// entity's handler methods
+func entityRoutes() string {
+	return builtinSource("entities/routes.tmpl")
+}
+
+func generateHexagonalEntityEdits(modulePath string, entity Entity) []wiring.Edit {
+	data := entityData{Entity: entity, ModulePath: modulePath}
+	service := entity.Var() + "Service inbound." + entity.Name + "Service"
+
+	return []wiring.Edit{
+		{File: "cmd/server/main.go", Kind: wiring.AddProvider, Code: "initiators.New" + entity.Name + "Repository"},
+		{File: "cmd/server/main.go", Kind: wiring.AddProvider, Code: "initiators.New" + entity.Name + "Service"},
+		{File: "adapters/inbound/http/router.go", Kind: wiring.AddParam, Func: "NewRouter", Code: service},
+		{
+			File: "adapters/inbound/http/router.go",
+			Kind: wiring.InsertStatements,
+			Func: "NewRouter",
+			Code: renderBuiltinTemplate("\t{{.Var}}Handler := New{{.Name}}Handler({{.Var}}Service, validator)\n\n"+entityRoutes(), data),
+		},
+		{File: "initiators/http.go", Kind: wiring.AddParam, Func: "NewHTTPHandler", Code: service},
+		{File: "initiators/http.go", Kind: wiring.AddCallArg, Func: "NewHTTPHandler", Call: "httphandler.NewRouter", Code: entity.Var() + "Service"},
+	}
+}
+
+// Clean Architecture Entity Generators
+
+// cleanEntityFiles maps the templated path of every file of an entity to its
+// source under entities/
+var cleanEntityFiles = map[string]string{
+	"internal/domain/entity/{{.File}}.go":                 "clean/internal/domain/entity/entity.go.tmpl",
+	"internal/domain/service/{{.File}}_service.go":        "clean/internal/domain/service/entity_service.go.tmpl",
+	"internal/storage/interfaces/{{.File}}_repository.go": "clean/internal/storage/interfaces/entity_repository.go.tmpl",
+	"internal/storage/mongo/{{.File}}_repository.go":      "clean/internal/storage/mongo/entity_repository.go.tmpl",
+	"internal/handler/rest/dto/{{.File}}_dto.go":          "clean/internal/handler/rest/dto/entity_dto.go.tmpl",
+	"internal/handler/rest/http/{{.File}}_handler.go":     "clean/internal/handler/rest/http/entity_handler.go.tmpl",
+	"internal/handler/rest/mapper/{{.File}}_mapper.go":    "clean/internal/handler/rest/mapper/entity_mapper.go.tmpl",
+	"initiator/{{.File}}.go":                              "clean/initiator/entity.go.tmpl",
+}
+
+func generateCleanEntityEdits(modulePath string, entity Entity) []wiring.Edit {
+	data := entityData{Entity: entity, ModulePath: modulePath}
+	handler := entity.Var() + "Handler *userhandler." + entity.Name + "Handler"
+
+	return []wiring.Edit{
+		{File: "cmd/server/main.go", Kind: wiring.AddProvider, Code: "initiator.New" + entity.Name + "Repository"},
+		{File: "cmd/server/main.go", Kind: wiring.AddProvider, Code: "initiator.New" + entity.Name + "Service"},
+		{File: "cmd/server/main.go", Kind: wiring.AddProvider, Code: "initiator.New" + entity.Name + "Mapper"},
+		{File: "cmd/server/main.go", Kind: wiring.AddProvider, Code: "initiator.New" + entity.Name + "Handler"},
+		{File: "internal/glue/routing/routes.go", Kind: wiring.AddParam, Func: "Routes", Code: handler},
+		{File: "internal/glue/routing/routes.go", Kind: wiring.InsertStatements, Func: "Routes", Code: renderBuiltinTemplate(entityRoutes(), data)},
+		{File: "initiator/handler.go", Kind: wiring.AddParam, Func: "NewRoutes", Code: handler},
+		{File: "initiator/handler.go", Kind: wiring.AddCallArg, Func: "NewRoutes", Call: "routing.Routes", Code: entity.Var() + "Handler"},
+	}
+}
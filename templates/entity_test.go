@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEntity(t *testing.T) {
+	for _, tc := range []struct {
+		name, fields string
+		// want lists the fields as Name:JSON:Type:Param
+		want []string
+		err  string
+	}{
+		{
+			name: "orderItem", fields: "total:float64, status:string,placed_at:time.Time,tags:[]string",
+			want: []string{"Total:total:float64:total", "Status:status:string:status", "PlacedAt:placed_at:time.Time:placedAt", "Tags:tags:[]string:tags"},
+		},
+		{
+			name: "Order", fields: "",
+		},
+		{
+			name: "Order", fields: "type:string,len:int,err:string,domain:string,order:int",
+			want: []string{"Type:type:string:typeValue", "Len:len:int:lenValue", "Err:err:string:errValue", "Domain:domain:string:domainValue", "Order:order:int:orderValue"},
+		},
+		{name: "order-item", err: "invalid entity name"},
+		{name: "Order", fields: "total", err: "expected name:type"},
+		{name: "Order", fields: ":int", err: "expected name:type"},
+		{name: "Order", fields: "total:decimal", err: "unsupported type"},
+		{name: "Order", fields: "2total:int", err: "invalid field name"},
+		{name: "Order", fields: "total:int,Total:float64", err: "defined twice"},
+		{name: "Order", fields: "id:int", err: "reserved"},
+		{name: "Order", fields: "created_at:time.Time", err: "reserved"},
+	} {
+		entity, err := ParseEntity(tc.name, tc.fields)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("ParseEntity(%q, %q) error = %v, want %q", tc.name, tc.fields, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseEntity(%q, %q): %v", tc.name, tc.fields, err)
+			continue
+		}
+
+		var got []string
+		for _, field := range entity.Fields {
+			got = append(got, strings.Join([]string{field.Name, field.JSON, field.Type, field.Param}, ":"))
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("ParseEntity(%q, %q) fields = %v, want %v", tc.name, tc.fields, got, tc.want)
+		}
+	}
+}
+
+func TestEntityNames(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		plural, vr, pluralVr string
+	}{
+		{"Order", "Orders", "order", "orders"},
+		{"OrderItem", "OrderItems", "orderItem", "orderItems"},
+		{"Category", "Categories", "category", "categories"},
+		{"Key", "Keys", "key", "keys"},
+		{"Box", "Boxes", "box", "boxes"},
+		{"Address", "Addresses", "address", "addresses"},
+		{"Batch", "Batches", "batch", "batches"},
+		{"Wish", "Wishes", "wish", "wishes"},
+		{"Y", "Ys", "y", "ys"},
+		{"Type", "Types", "typeEntity", "types"},
+		{"Error", "Errors", "errorEntity", "errors"},
+		{"Domain", "Domains", "domainEntity", "domains"},
+		{"Func", "Funcs", "funcEntity", "funcs"},
+	} {
+		entity := Entity{Name: tc.name}
+		if got := entity.Plural(); got != tc.plural {
+			t.Errorf("%s: Plural() = %q, want %q", tc.name, got, tc.plural)
+		}
+		if got := entity.Var(); got != tc.vr {
+			t.Errorf("%s: Var() = %q, want %q", tc.name, got, tc.vr)
+		}
+		if got := entity.PluralVar(); got != tc.pluralVr {
+			t.Errorf("%s: PluralVar() = %q, want %q", tc.name, got, tc.pluralVr)
+		}
+	}
+}
+
+func TestNameCases(t *testing.T) {
+	for _, tc := range []struct {
+		name, snake, unexported string
+	}{
+		{"Order", "order", "order"},
+		{"OrderItem", "order_item", "orderItem"},
+		{"HTTPServer", "http_server", "httpServer"},
+		{"ID", "id", "id"},
+		{"UserID", "user_id", "userID"},
+		{"PlacedAt", "placed_at", "placedAt"},
+		{"order", "order", "order"},
+		{"", "", ""},
+	} {
+		if got := snakeCase(tc.name); got != tc.snake {
+			t.Errorf("snakeCase(%q) = %q, want %q", tc.name, got, tc.snake)
+		}
+		if got := unexported(tc.name); got != tc.unexported {
+			t.Errorf("unexported(%q) = %q, want %q", tc.name, got, tc.unexported)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dawit-go/small-go/wiring"
+)
+
+// Feature is an optional module added on top of a template with --with. It
+// contributes files, dependencies, fx providers and configuration keys.
+type Feature interface {
+	Name() string
+	Description() string
+	// Templates lists the templates the feature can be added to
+	Templates() []string
+	// Conflicts lists the features that cannot be combined with this one
+	Conflicts() []string
+	GetDependencies() []string
+	// ConfigKeys lists the environment variables the feature reads
+	ConfigKeys() []ConfigKey
+	// GenerateFiles returns the files the feature adds to a project generated
+	// from the named template and the edits registering them in its files
+	GenerateFiles(template, modulePath string) (map[string]string, []wiring.Edit)
+}
+
+// ConfigKey is an environment variable read by the generated code
+type ConfigKey struct {
+	Name        string
+	Default     string
+	Description string
+}
+
+// GetAvailableFeatures returns all available features
+func GetAvailableFeatures() []Feature {
+	return []Feature{
+		postgresFeature,
+		mongoFeature,
+		redisFeature,
+		authJWTFeature,
+		otelFeature,
+	}
+}
+
+// renamedFeatures maps the former names of features, which lockfiles may
+// still record, to their current names
+var renamedFeatures = map[string]string{
+	"jwt": "auth-jwt",
+}
+
+// GetFeatureByName returns a feature by name
+func GetFeatureByName(name string) Feature {
+	if renamed, ok := renamedFeatures[name]; ok {
+		name = renamed
+	}
+	for _, feature := range GetAvailableFeatures() {
+		if feature.Name() == name {
+			return feature
+		}
+	}
+	return nil
+}
+
+// WithFeatures returns the template with the named features added on top of
+// it. Features are applied in the order GetAvailableFeatures lists them, so
+// the output does not depend on the order they were given in.
+func WithFeatures(template Template, names []string) (Template, error) {
+	if len(names) == 0 {
+		return template, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range names {
+		feature := GetFeatureByName(name)
+		if feature == nil {
+			return nil, fmt.Errorf("unknown feature: %s. Use 'small-go list features' to see available features", name)
+		}
+		if supportedTemplate(feature, template) == "" {
+			return nil, fmt.Errorf("feature %s cannot be added to template %s, only to %s",
+				name, template.Name(), strings.Join(feature.Templates(), ", "))
+		}
+		selected[feature.Name()] = true
+	}
+
+	var features []Feature
+	for _, feature := range GetAvailableFeatures() {
+		if !selected[feature.Name()] {
+			continue
+		}
+		for _, other := range features {
+			if conflicts(feature, other) {
+				return nil, fmt.Errorf("features %s and %s cannot be combined", other.Name(), feature.Name())
+			}
+		}
+		features = append(features, feature)
+	}
+
+	return &FeatureTemplate{base: template, features: features}, nil
+}
+
+// supportedTemplate returns the name of the template or of the first
+// template it extends that the feature supports, or "" if there is none
+func supportedTemplate(feature Feature, template Template) string {
+	for ; template != nil; template = parentOf(template) {
+		for _, name := range feature.Templates() {
+			if name == template.Name() {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// conflicts reports whether either feature declares a conflict with the other
+func conflicts(a, b Feature) bool {
+	for _, name := range a.Conflicts() {
+		if name == b.Name() {
+			return true
+		}
+	}
+	for _, name := range b.Conflicts() {
+		if name == a.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureTemplate is a template with features added on top of it. It is
+// named after its base template, whose files it generates together with
+// those of the features.
+type FeatureTemplate struct {
+	base     Template
+	features []Feature
+}
+
+func (t *FeatureTemplate) Name() string {
+	return t.base.Name()
+}
+
+func (t *FeatureTemplate) Description() string {
+	return fmt.Sprintf("%s, with %s", t.base.Description(), strings.Join(t.Features(), ", "))
+}
+
+// GenerateFiles renders the template and its features.
+// Render errors result in an empty map; use RenderFiles to observe them.
+func (t *FeatureTemplate) GenerateFiles(projectName string) map[string]string {
+	files, err := t.Render(Params{ProjectName: projectName})
+	if err != nil {
+		return map[string]string{}
+	}
+	return files
+}
+
+// GetDependencies returns the dependencies of the template followed by
+// those of the features it does not declare yet
+func (t *FeatureTemplate) GetDependencies() []string {
+	dependencies := append([]string{}, t.base.GetDependencies()...)
+	seen := make(map[string]bool)
+	for _, dependency := range dependencies {
+		seen[strings.Split(dependency, "@")[0]] = true
+	}
+	for _, feature := range t.features {
+		for _, dependency := range feature.GetDependencies() {
+			if path := strings.Split(dependency, "@")[0]; !seen[path] {
+				seen[path] = true
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+	return dependencies
+}
+
+// Base returns the template the features are added to
+func (t *FeatureTemplate) Base() Template {
+	return t.base
+}
+
+// Features returns the names of the added features
+func (t *FeatureTemplate) Features() []string {
+	names := make([]string, len(t.features))
+	for i, feature := range t.features {
+		names[i] = feature.Name()
+	}
+	return names
+}
+
+// Variables returns the variables of the base template
+func (t *FeatureTemplate) Variables() []Variable {
+	return GetVariables(t.base)
+}
+
+// Render generates the files of the base template, adds the files of every
+// feature, registers them through their edits and lists their configuration
+// keys in .env.example
+func (t *FeatureTemplate) Render(params Params) (map[string]string, error) {
+	files, err := RenderFiles(t.base, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, feature := range t.features {
+		featureFiles, edits := feature.GenerateFiles(supportedTemplate(feature, t.base), params.Module())
+		for filePath, content := range featureFiles {
+			// Features may share a file, such as the code they plug into
+			if existing, ok := files[filePath]; ok && existing != content {
+				return nil, fmt.Errorf("feature %s generates %s, which is already generated with different content", feature.Name(), filePath)
+			}
+			files[filePath] = content
+		}
+
+		contents := make(map[string][]byte, len(edits))
+		for _, edit := range edits {
+			if content, ok := files[edit.File]; ok {
+				contents[edit.File] = []byte(content)
+			}
+		}
+		changes, err := wiring.PlanFiles(contents, edits)
+		if err != nil {
+			return nil, fmt.Errorf("cannot add feature %s to template %s:\n%w", feature.Name(), t.base.Name(), err)
+		}
+		for filePath, content := range changes {
+			files[filePath] = string(content)
+		}
+	}
+
+	if env := t.envExample(); env != "" {
+		files[".env.example"] = env
+	}
+	return files, nil
+}
+
+// envExample lists the configuration keys of the features with their
+// defaults, or returns "" if none has any
+func (t *FeatureTemplate) envExample() string {
+	var b strings.Builder
+	for _, feature := range t.features {
+		keys := feature.ConfigKeys()
+		if len(keys) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n# %s\n", feature.Name())
+		for _, key := range keys {
+			fmt.Fprintf(&b, "# %s\n%s=%s\n", key.Description, key.Name, key.Default)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "# Environment variables read by the service, with their defaults\nPORT=8080\n" + b.String()
+}
+
+// builtinFeature is a feature whose files are text/template sources
+// rendered with the module path and the initializer package of the template
+type builtinFeature struct {
+	name         string
+	description  string
+	conflicts    []string
+	dependencies []string
+	config       []ConfigKey
+	// files maps the templated path of every file to its source under features/
+	files map[string]string
+	// providers are added to fx.Provide, decorators and invokes to fx.New as
+	// fx.Decorate and fx.Invoke options, all qualified with the initializer
+	// package. Features sharing a decorator add it once.
+	providers  []string
+	decorators []string
+	invokes    []string
+	// layouts maps the templates the feature supports to their layout
+	layouts map[string]featureLayout
+}
+
+// featureLayout describes where a template keeps its dependency injection code
+type featureLayout struct {
+	// Package is the directory and name of the package holding the fx providers
+	Package string
+	// Main is the file calling fx.New
+	Main string
+	// HTTP is the directory of the package serving HTTP requests
+	HTTP string
+}
+
+// featureData holds the values available to feature templates
+type featureData struct {
+	featureLayout
+	ModulePath string
+}
+
+func (f *builtinFeature) Name() string {
+	return f.name
+}
+
+func (f *builtinFeature) Description() string {
+	return f.description
+}
+
+func (f *builtinFeature) Templates() []string {
+	names := make([]string, 0, len(f.layouts))
+	for name := range f.layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *builtinFeature) Conflicts() []string {
+	return f.conflicts
+}
+
+func (f *builtinFeature) GetDependencies() []string {
+	return f.dependencies
+}
+
+func (f *builtinFeature) ConfigKeys() []ConfigKey {
+	return f.config
+}
+
+func (f *builtinFeature) GenerateFiles(template, modulePath string) (map[string]string, []wiring.Edit) {
+	layout, ok := f.layouts[template]
+	if !ok {
+		return nil, nil
+	}
+	data := featureData{featureLayout: layout, ModulePath: modulePath}
+
+	files := make(map[string]string, len(f.files))
+	for filePath, source := range f.files {
+		files[renderBuiltinTemplate(filePath, data)] = renderBuiltinTemplate(builtinSource("features/"+source), data)
+	}
+
+	var edits []wiring.Edit
+	for _, provider := range f.providers {
+		edits = append(edits, wiring.Edit{File: layout.Main, Kind: wiring.AddProvider, Code: layout.Package + "." + provider})
+	}
+	for _, decorator := range f.decorators {
+		edits = append(edits, wiring.Edit{
+			File: layout.Main,
+			Kind: wiring.AddCallArg,
+			Func: "main",
+			Call: "fx.New",
+			Code: "fx.Decorate(" + layout.Package + "." + decorator + ")",
+		})
+	}
+	for _, invoke := range f.invokes {
+		edits = append(edits, wiring.Edit{
+			File: layout.Main,
+			Kind: wiring.AddCallArg,
+			Func: "main",
+			Call: "fx.New",
+			Code: "fx.Invoke(" + layout.Package + "." + invoke + ")",
+		})
+	}
+	return files, edits
+}
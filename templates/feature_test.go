@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithFeatures(t *testing.T) {
+	hexagonal := GetTemplateByName("hexagonal")
+
+	for _, tc := range []struct {
+		template Template
+		features []string
+		err      string
+	}{
+		{hexagonal, []string{"postgres", "mongo"}, "features postgres and mongo cannot be combined"},
+		{GetTemplateByName("clean"), []string{"postgres"}, "feature postgres cannot be added to template clean"},
+		{hexagonal, []string{"mysql"}, "unknown feature: mysql"},
+	} {
+		if _, err := WithFeatures(tc.template, tc.features); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("WithFeatures(%s, %v) = %v, want an error containing %q", tc.template.Name(), tc.features, err, tc.err)
+		}
+	}
+
+	// The output does not depend on the order the features are given in, and
+	// former names select the same feature
+	a, err := WithFeatures(hexagonal, []string{"otel", "auth-jwt", "postgres"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := WithFeatures(hexagonal, []string{"postgres", "jwt", "otel", "auth-jwt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filesA, err := RenderFiles(a, Params{ProjectName: "example.com/features"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filesB, err := RenderFiles(b, Params{ProjectName: "example.com/features"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filesA) != len(filesB) {
+		t.Fatalf("got %d and %d files", len(filesA), len(filesB))
+	}
+	for filePath, content := range filesA {
+		if filesB[filePath] != content {
+			t.Errorf("%s differs with the features given in another order", filePath)
+		}
+	}
+
+	main := filesA["cmd/server/main.go"]
+	for _, code := range []string{"initiators.NewPostgresPool,", "fx.Invoke(initiators.ConnectPostgres)", "fx.Decorate(initiators.WithJWTAuthentication)", "initiators.NewTracerProvider,", "fx.Decorate(initiators.ApplyMiddleware)"} {
+		if strings.Count(main, code) != 1 {
+			t.Errorf("cmd/server/main.go should contain %s once:\n%s", code, main)
+		}
+	}
+	for _, key := range []string{"DATABASE_URL=", "JWT_SECRET=", "OTEL_EXPORTER_OTLP_ENDPOINT="} {
+		if !strings.Contains(filesA[".env.example"], key) {
+			t.Errorf(".env.example lacks %s:\n%s", key, filesA[".env.example"])
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dawit-go/small-go/textdiff"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current template output")
+
+// goldenProject is the project name every template is generated with
+const goldenProject = "example.com/golden"
+
+// goldenDir holds one directory of snapshots per template. Files are stored
+// under their generated path with a .golden suffix so that the go tool and
+// gofmt leave them alone.
+const goldenDir = "testdata/golden"
+
+// goldenCase is a template rendered with one set of variables; its
+// snapshots are stored under testdata/golden/<name>
+type goldenCase struct {
+	name     string
+	template Template
+	vars     map[string]string
+}
+
+// goldenCases renders every template with its default variables, and once
+// more for each bool variable switched away from its default, so that code
+// only generated behind a flag is covered too
+func goldenCases() []goldenCase {
+	var cases []goldenCase
+	// Plugins on PATH are not part of this repository
+	for _, template := range localTemplates() {
+		cases = append(cases, goldenCase{name: template.Name(), template: template})
+		for _, v := range GetVariables(template) {
+			if v.Type != "bool" {
+				continue
+			}
+			value := "true"
+			if v.Default == "true" {
+				value = "false"
+			}
+			cases = append(cases, goldenCase{
+				name:     template.Name() + "-" + v.Name,
+				template: template,
+				vars:     map[string]string{v.Name: value},
+			})
+		}
+	}
+	return cases
+}
+
+func TestGoldenFiles(t *testing.T) {
+	for _, tc := range goldenCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := RenderFiles(tc.template, Params{ProjectName: goldenProject, Vars: tc.vars})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(goldenDir, tc.name)
+
+			if *update {
+				writeGolden(t, dir, files)
+				return
+			}
+
+			golden := readGolden(t, dir)
+			for _, filePath := range sortedKeys(files) {
+				want, ok := golden[filePath]
+				if !ok {
+					t.Errorf("%s is generated but has no golden file; run go test ./templates -update", filePath)
+					continue
+				}
+				if diff := textdiff.Unified("golden/"+filePath, "generated/"+filePath, want, files[filePath], 3); diff != "" {
+					t.Errorf("%s differs from its golden file; run go test ./templates -update if this is intended:\n%s", filePath, diff)
+				}
+			}
+			for _, filePath := range sortedKeys(golden) {
+				if _, ok := files[filePath]; !ok {
+					t.Errorf("%s has a golden file but is no longer generated; run go test ./templates -update", filePath)
+				}
+			}
+		})
+	}
+}
+
+// readGolden returns the snapshots of a template keyed by generated path
+func readGolden(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	golden := make(map[string]string)
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		golden[strings.TrimSuffix(filepath.ToSlash(rel), ".golden")] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read golden files: %v; run go test ./templates -update to create them", err)
+	}
+	return golden
+}
+
+// writeGolden replaces the snapshots of a template with files
+func writeGolden(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for filePath, content := range files {
+		goldenPath := filepath.Join(dir, filepath.FromSlash(filePath)+".golden")
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,6 +1,9 @@
 package templates
 
+import "github.com/dawit-go/small-go/wiring"
+
 // HexagonalTemplate represents the hexagonal architecture template
+// rendered from the files embedded under builtin/hexagonal
 type HexagonalTemplate struct{}
 
 func (h *HexagonalTemplate) Name() string {
@@ -8,30 +11,27 @@ func (h *HexagonalTemplate) Name() string {
 }
 
 func (h *HexagonalTemplate) Description() string {
-	return "Hexagonal Architecture (Ports & Adapters) with Uber FX and Chi Router"
+	return hexagonalFiles().Description()
 }
 
 func (h *HexagonalTemplate) GenerateFiles(projectName string) map[string]string {
-	return map[string]string{
-		"cmd/server/main.go":                               generateMainGo(projectName),
-		"internal/domain/user.go":                          generateDomainUser(),
-		"internal/application/user_service.go":             generateApplicationUserService(projectName),
-		"internal/ports/inbound/user_service.go":           generateInboundUserService(projectName),
-		"internal/ports/outbound/user_repository.go":       generateOutboundUserRepository(projectName),
-		"adapters/inbound/http/user_handler.go":            generateHTTPUserHandler(projectName),
-		"adapters/inbound/http/router.go":                  generateHTTPRouter(projectName),
-		"adapters/outbound/persistence/user_repository.go": generateUserRepository(projectName),
-		"initiators/app.go":                                generateAppInitiator(),
-		"initiators/http.go":                               generateHTTPInitiator(projectName),
-		"initiators/persistence.go":                        generatePersistenceInitiator(projectName),
-		"README.md":                                        generateREADME(projectName, "hexagonal"),
-	}
+	return hexagonalFiles().GenerateFiles(projectName)
+}
+
+// Render generates the files with their imports rooted at the module path
+func (h *HexagonalTemplate) Render(params Params) (map[string]string, error) {
+	return hexagonalFiles().Render(params)
+}
+
+// Variables returns the options of the template
+func (h *HexagonalTemplate) Variables() []Variable {
+	return hexagonalFiles().Variables()
 }
 
 func (h *HexagonalTemplate) GetDependencies() []string {
-	return []string{
-		"github.com/go-chi/chi/v5",
-		"go.uber.org/fx",
-		"go.uber.org/zap",
-	}
+	return hexagonalFiles().GetDependencies()
+}
+
+func (h *HexagonalTemplate) GenerateEntity(modulePath string, entity Entity) (map[string]string, []wiring.Edit) {
+	return renderEntityFiles(hexagonalEntityFiles, modulePath, entity), generateHexagonalEntityEdits(modulePath, entity)
 }
@@ -1,5 +1,10 @@
 package templates
 
+import (
+	"fmt"
+	"slices"
+)
+
 // Template defines the interface for project templates
 type Template interface {
 	Name() string
@@ -8,12 +13,49 @@ type Template interface {
 	GetDependencies() []string
 }
 
-// GetAvailableTemplates returns all available templates
+// Params holds the inputs a template is rendered with
+type Params struct {
+	ProjectName string
+	// ModulePath is the Go module path; the project name is used if it is empty
+	ModulePath string
+	Vars       map[string]string
+}
+
+// Module returns the module path generated imports are rooted at
+func (p Params) Module() string {
+	if p.ModulePath != "" {
+		return p.ModulePath
+	}
+	return p.ProjectName
+}
+
+// Renderer is implemented by templates whose files can fail to render
+type Renderer interface {
+	Render(params Params) (map[string]string, error)
+}
+
+// registered holds templates added at runtime, such as directory templates
+var registered []Template
+
+// GetAvailableTemplates returns all available templates: the built-in ones,
+// those registered at runtime and the plugins on PATH they do not hide
 func GetAvailableTemplates() []Template {
-	return []Template{
+	available := localTemplates()
+	for _, plugin := range plugins() {
+		if !slices.ContainsFunc(available, func(t Template) bool { return t.Name() == plugin.Name() }) {
+			available = append(available, plugin)
+		}
+	}
+	return available
+}
+
+// localTemplates returns the built-in and registered templates
+func localTemplates() []Template {
+	available := []Template{
 		&HexagonalTemplate{},
 		&CleanTemplate{},
 	}
+	return append(available, registered...)
 }
 
 // GetTemplateByName returns a template by name
@@ -25,3 +67,38 @@ func GetTemplateByName(name string) Template {
 	}
 	return nil
 }
+
+// Register makes a template available next to the built-in ones, hiding
+// any plugin of the same name
+func Register(template Template) error {
+	for _, existing := range localTemplates() {
+		if existing.Name() == template.Name() {
+			return fmt.Errorf("template %s is already defined", template.Name())
+		}
+	}
+	registered = append(registered, template)
+	return nil
+}
+
+// RenderFiles generates the files of a template, reporting render errors
+// for templates that implement Renderer
+func RenderFiles(template Template, params Params) (map[string]string, error) {
+	if renderer, ok := template.(Renderer); ok {
+		return renderer.Render(params)
+	}
+	if len(params.Vars) > 0 {
+		return nil, fmt.Errorf("template %s does not accept variables", template.Name())
+	}
+	if params.Module() != params.ProjectName {
+		return nil, fmt.Errorf("template %s does not support a module path other than the project name", template.Name())
+	}
+	return template.GenerateFiles(params.ProjectName), nil
+}
+
+// GetVariables returns the variables declared by a template, if any
+func GetVariables(template Template) []Variable {
+	if provider, ok := template.(VariableProvider); ok {
+		return provider.Variables()
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest file inside a template directory
+const ManifestFile = "template.yaml"
+
+// Variable types supported in manifests
+const (
+	VarString = "string"
+	VarBool   = "bool"
+	VarInt    = "int"
+	VarEnum   = "enum"
+)
+
+// Manifest describes a template directory
+type Manifest struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Extends names a template whose files are generated first; files of
+	// this template replace those with the same path
+	Extends string `yaml:"extends"`
+	// Delete lists paths of the extended template to leave out, as
+	// path.Match patterns or directories ending in "/"
+	Delete       []string          `yaml:"delete"`
+	Dependencies []string          `yaml:"dependencies"`
+	Variables    []Variable        `yaml:"variables"`
+	Files        []ConditionalFile `yaml:"files"`
+}
+
+// Variable is a value the user provides when generating a project
+type Variable struct {
+	Name     string   `yaml:"name" json:"name"`
+	Type     string   `yaml:"type" json:"type,omitempty"`
+	Prompt   string   `yaml:"prompt" json:"prompt,omitempty"`
+	Default  string   `yaml:"default" json:"default,omitempty"`
+	Options  []string `yaml:"options" json:"options,omitempty"`
+	Pattern  string   `yaml:"pattern" json:"pattern,omitempty"`
+	Required bool     `yaml:"required" json:"required,omitempty"`
+}
+
+// ConditionalFile limits files matching Path to projects where When holds.
+// Path is a path.Match pattern or a directory prefix ending in "/", and When
+// is a text/template pipeline such as `.Vars.docker` or `eq .Vars.db "postgres"`.
+type ConditionalFile struct {
+	Path string `yaml:"path"`
+	When string `yaml:"when"`
+
+	condition *template.Template
+}
+
+// VariableProvider is implemented by templates that declare variables
+type VariableProvider interface {
+	Variables() []Variable
+}
+
+// readManifest reads the manifest of a template directory, returning nil if there is none
+func readManifest(fsys fs.FS) (*Manifest, error) {
+	content, err := fs.ReadFile(fsys, ManifestFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+
+	return &manifest, nil
+}
+
+// validate checks the manifest and compiles its file conditions
+func (m *Manifest) validate() error {
+	for _, dependency := range m.Dependencies {
+		if _, err := ParseDependency(dependency); err != nil {
+			return err
+		}
+	}
+
+	if len(m.Delete) > 0 && m.Extends == "" {
+		return errors.New("delete needs a template to extend")
+	}
+	for _, pattern := range m.Delete {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("delete %s has invalid pattern: %w", pattern, err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range m.Variables {
+		v := &m.Variables[i]
+		if v.Name == "" {
+			return fmt.Errorf("variable %d has no name", i+1)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variable %s is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+
+		switch v.Type {
+		case "":
+			v.Type = VarString
+		case VarString, VarBool, VarInt:
+		case VarEnum:
+			if len(v.Options) == 0 {
+				return fmt.Errorf("enum variable %s has no options", v.Name)
+			}
+		default:
+			return fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+		}
+
+		if v.Pattern != "" {
+			if _, err := regexp.Compile(v.Pattern); err != nil {
+				return fmt.Errorf("variable %s has invalid pattern: %w", v.Name, err)
+			}
+		}
+		if v.Default != "" {
+			if _, err := v.Parse(v.Default); err != nil {
+				return fmt.Errorf("invalid default: %w", err)
+			}
+		}
+	}
+
+	for i := range m.Files {
+		f := &m.Files[i]
+		if f.Path == "" || f.When == "" {
+			return fmt.Errorf("file rule %d needs both path and when", i+1)
+		}
+		if _, err := path.Match(f.Path, ""); err != nil {
+			return fmt.Errorf("file rule %s has invalid pattern: %w", f.Path, err)
+		}
+		condition, err := template.New(f.Path).Option("missingkey=error").Parse("{{if " + f.When + "}}true{{end}}")
+		if err != nil {
+			return fmt.Errorf("file rule %s has invalid condition: %w", f.Path, err)
+		}
+		f.condition = condition
+	}
+
+	return nil
+}
+
+// Matches reports whether the rule applies to the generated file path
+func (f *ConditionalFile) Matches(filePath string) bool {
+	return matchPath(f.Path, filePath)
+}
+
+// Deletes reports whether the file path of the extended template is left out
+func (m *Manifest) Deletes(filePath string) bool {
+	for _, pattern := range m.Delete {
+		if matchPath(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether a generated file path matches a path.Match
+// pattern or lies below a directory pattern ending in "/"
+func matchPath(pattern, filePath string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(filePath, pattern)
+	}
+	matched, _ := path.Match(pattern, filePath)
+	return matched
+}
+
+// Include evaluates the rule's condition against the template data
+func (f *ConditionalFile) Include(data TemplateData) (bool, error) {
+	var buf bytes.Buffer
+	if err := f.condition.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("failed to evaluate condition for %s: %w", f.Path, err)
+	}
+	return buf.String() == "true", nil
+}
+
+// Label returns the text shown when prompting for the variable
+func (v Variable) Label() string {
+	if v.Prompt != "" {
+		return v.Prompt
+	}
+	return v.Name
+}
+
+// Parse validates a raw value and converts it to the variable's type
+func (v Variable) Parse(raw string) (any, error) {
+	switch v.Type {
+	case VarBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s must be true or false, got %q", v.Name, raw)
+		}
+		return value, nil
+	case VarInt:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s must be a whole number, got %q", v.Name, raw)
+		}
+		return value, nil
+	case VarEnum:
+		for _, option := range v.Options {
+			if raw == option {
+				return raw, nil
+			}
+		}
+		return nil, fmt.Errorf("variable %s must be one of %s, got %q", v.Name, strings.Join(v.Options, ", "), raw)
+	default:
+		if v.Required && raw == "" {
+			return nil, fmt.Errorf("variable %s is required", v.Name)
+		}
+		if v.Pattern != "" && raw != "" && !regexp.MustCompile(v.Pattern).MatchString(raw) {
+			return nil, fmt.Errorf("variable %s must match %s, got %q", v.Name, v.Pattern, raw)
+		}
+		return raw, nil
+	}
+}
+
+// ResolveVariables applies defaults to the raw values and converts them to typed values
+func ResolveVariables(variables []Variable, values map[string]string) (map[string]any, error) {
+	declared := make(map[string]bool, len(variables))
+	resolved := make(map[string]any, len(variables))
+
+	for _, v := range variables {
+		declared[v.Name] = true
+
+		raw, ok := values[v.Name]
+		if !ok {
+			raw = v.Default
+		}
+		if !ok && raw == "" && v.Type != VarString {
+			if v.Required {
+				return nil, fmt.Errorf("variable %s is required", v.Name)
+			}
+			raw = v.zero()
+		}
+
+		value, err := v.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		resolved[v.Name] = value
+	}
+
+	for name := range values {
+		if !declared[name] {
+			return nil, fmt.Errorf("unknown variable: %s", name)
+		}
+	}
+
+	return resolved, nil
+}
+
+// zero returns the raw value used when an optional variable has no default
+func (v Variable) zero() string {
+	switch v.Type {
+	case VarBool:
+		return "false"
+	case VarInt:
+		return "0"
+	case VarEnum:
+		return v.Options[0]
+	default:
+		return ""
+	}
+}
+
+// sample returns a typed value used to check templates when they are loaded
+func (v Variable) sample() any {
+	switch v.Type {
+	case VarBool:
+		value, _ := strconv.ParseBool(v.Default)
+		return value
+	case VarInt:
+		value, _ := strconv.Atoi(v.Default)
+		return value
+	case VarEnum:
+		if v.Default != "" {
+			return v.Default
+		}
+		return v.Options[0]
+	default:
+		if v.Default != "" {
+			return v.Default
+		}
+		return "example"
+	}
+}
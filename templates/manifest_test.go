@@ -0,0 +1,178 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name, manifest, err string
+	}{
+		{"unknown type", "variables:\n  - name: port\n    type: float\n", `variable port has unknown type "float"`},
+		{"unnamed variable", "variables:\n  - type: bool\n", "variable 1 has no name"},
+		{"duplicate variable", "variables:\n  - name: db\n  - name: db\n", "variable db is declared twice"},
+		{"enum without options", "variables:\n  - name: db\n    type: enum\n", "enum variable db has no options"},
+		{"enum default not an option", "variables:\n  - name: db\n    type: enum\n    options: [none, postgres]\n    default: mysql\n", "variable db must be one of none, postgres"},
+		{"bool default", "variables:\n  - name: docker\n    type: bool\n    default: yes\n", "variable docker must be true or false"},
+		{"int default", "variables:\n  - name: replicas\n    type: int\n    default: two\n", "variable replicas must be a whole number"},
+		{"string default not matching the pattern", "variables:\n  - name: owner\n    pattern: \"^[a-z]+$\"\n    default: ACME\n", "variable owner must match"},
+		{"invalid pattern", "variables:\n  - name: owner\n    pattern: \"[a-z\"\n", "variable owner has invalid pattern"},
+		{"invalid dependency", "dependencies:\n  - github.com/acme/log@latest\n", "not a semantic version"},
+		{"delete without extends", "delete:\n  - README.md\n", "delete needs a template to extend"},
+		{"file rule without condition", "files:\n  - path: Dockerfile\n", "file rule 1 needs both path and when"},
+		{"invalid file condition", "files:\n  - path: Dockerfile\n    when: eq (.Vars.db\n", "file rule Dockerfile has invalid condition"},
+	} {
+		_, err := readManifest(fstest.MapFS{ManifestFile: {Data: []byte(tc.manifest)}})
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error = %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	manifest, err := readManifest(fstest.MapFS{ManifestFile: {Data: []byte("variables:\n  - name: owner\n")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := manifest.Variables[0].Type; got != VarString {
+		t.Errorf("variables default to type %q, got %q", VarString, got)
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	variables := []Variable{
+		{Name: "owner", Pattern: "^[a-z-]+$"},
+		{Name: "docker", Type: VarBool, Default: "true"},
+		{Name: "metrics", Type: VarBool},
+		{Name: "replicas", Type: VarInt},
+		{Name: "db", Type: VarEnum, Options: []string{"none", "postgres"}},
+	}
+
+	resolved, err := ResolveVariables(variables, map[string]string{"owner": "payments", "replicas": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"owner": "payments", "docker": true, "metrics": false, "replicas": 3, "db": "none"}
+	for name, value := range want {
+		if resolved[name] != value {
+			t.Errorf("%s = %#v, want %#v", name, resolved[name], value)
+		}
+	}
+
+	for _, tc := range []struct {
+		values map[string]string
+		err    string
+	}{
+		{map[string]string{"docker": "maybe"}, `variable docker must be true or false, got "maybe"`},
+		{map[string]string{"replicas": "1.5"}, `variable replicas must be a whole number, got "1.5"`},
+		{map[string]string{"db": "mysql"}, `variable db must be one of none, postgres, got "mysql"`},
+		{map[string]string{"owner": "Payments"}, `variable owner must match ^[a-z-]+$, got "Payments"`},
+		{map[string]string{"region": "eu"}, "unknown variable: region"},
+	} {
+		if _, err := ResolveVariables(variables, tc.values); err == nil || err.Error() != tc.err {
+			t.Errorf("%v: error = %v, want %q", tc.values, err, tc.err)
+		}
+	}
+
+	required := []Variable{{Name: "replicas", Type: VarInt, Required: true}}
+	if _, err := ResolveVariables(required, nil); err == nil || err.Error() != "variable replicas is required" {
+		t.Errorf("missing required variable: error = %v", err)
+	}
+}
+
+func TestConditionalFiles(t *testing.T) {
+	template, err := LoadFSTemplate("conditional", "memory", fstest.MapFS{
+		ManifestFile: {Data: []byte(`
+variables:
+  - name: db
+    type: enum
+    options: [none, postgres]
+  - name: replicas
+    type: int
+    default: 1
+files:
+  - path: migrations/
+    when: eq .Vars.db "postgres"
+  - path: "*.yaml"
+    when: gt .Vars.replicas 1
+`)},
+		"main.go.tmpl":                 {Data: []byte("package main\n")},
+		"deploy.yaml.tmpl":             {Data: []byte("replicas: {{.Vars.replicas}}\n")},
+		"migrations/001_init.sql.tmpl": {Data: []byte("CREATE TABLE users ();\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		vars map[string]string
+		want []string
+	}{
+		{nil, []string{"main.go"}},
+		{map[string]string{"db": "postgres"}, []string{"main.go", "migrations/001_init.sql"}},
+		{map[string]string{"replicas": "3"}, []string{"deploy.yaml", "main.go"}},
+	} {
+		files, err := RenderFiles(template, Params{ProjectName: "svc", Vars: tc.vars})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(sortedKeys(files), ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%v: files = %v, want %v", tc.vars, sortedKeys(files), tc.want)
+		}
+	}
+}
+
+func TestLoadDirTemplate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "acme-service")
+	for name, content := range map[string]string{
+		"main.go.tmpl": "package main\n\n// {{.ProjectName}} is owned by {{.Vars.owner}}\n",
+		"README.md":    "# {{.ProjectName}}\n",
+	} {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manifest := "description: ACME service\nvariables:\n  - name: owner\n    default: payments\n"
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	template, err := LoadDirTemplate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Name() != "acme-service" || template.Description() != "ACME service" {
+		t.Errorf("template = %s: %s, want it named after its directory", template.Name(), template.Description())
+	}
+	files, err := RenderFiles(template, Params{ProjectName: "svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := files["main.go"]; got != "package main\n\n// svc is owned by payments\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if _, ok := files[ManifestFile]; ok {
+		t.Errorf("%s was generated", ManifestFile)
+	}
+
+	// The manifest name replaces the directory name
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte("name: acme\n"+manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if template, err := LoadDirTemplate(dir); err != nil || template.Name() != "acme" {
+		t.Errorf("template with a manifest name: %v, %v", template, err)
+	}
+
+	if _, err := LoadDirTemplate(filepath.Join(dir, "main.go.tmpl")); err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Errorf("loading a file: error = %v", err)
+	}
+	if _, err := LoadDirTemplate(filepath.Join(dir, "missing")); err == nil {
+		t.Error("loading a missing directory should fail")
+	}
+}
@@ -0,0 +1,282 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PluginPrefix starts the names of executables on PATH that act as templates;
+// the rest of the name is the template's name
+const PluginPrefix = "small-go-template-"
+
+// PluginProtocol is the version of the protocol spoken with plugins
+const PluginProtocol = 1
+
+// pluginTimeout bounds every run of a plugin, so that a plugin that hangs
+// does not hang small-go with it
+var pluginTimeout = time.Minute
+
+// Plugin actions
+const (
+	ActionDescribe = "describe"
+	ActionGenerate = "generate"
+)
+
+// PluginRequest is written as JSON to the standard input of a plugin
+type PluginRequest struct {
+	Protocol int    `json:"protocol"`
+	Action   string `json:"action"`
+	// The project to generate, for the generate action
+	ProjectName string         `json:"project_name,omitempty"`
+	ModulePath  string         `json:"module_path,omitempty"`
+	Variables   map[string]any `json:"variables,omitempty"`
+}
+
+// PluginDescription is what a plugin writes to standard output for the
+// describe action
+type PluginDescription struct {
+	Description string     `json:"description"`
+	Variables   []Variable `json:"variables,omitempty"`
+}
+
+// PluginResponse is what a plugin writes to standard output for the
+// generate action: the content of every file by slash-separated path, and
+// the dependencies of the generated code as "path" or "path@version"
+type PluginResponse struct {
+	Files        map[string]string `json:"files"`
+	Dependencies []string          `json:"dependencies,omitempty"`
+}
+
+// DependencyRenderer is implemented by templates whose dependencies are
+// only known once the project is rendered
+type DependencyRenderer interface {
+	RenderDependencies(params Params) ([]string, error)
+}
+
+// PluginTemplate is a template generated by an executable that reads a
+// PluginRequest from standard input and writes its answer to standard output.
+// A plugin exits with a non-zero status to report an error on standard error.
+type PluginTemplate struct {
+	name string
+	path string
+
+	describeOnce sync.Once
+	description  PluginDescription
+	describeErr  error
+
+	// The last generation is kept, as rendering and resolving the
+	// dependencies of the same project would otherwise run the plugin twice
+	mu           sync.Mutex
+	lastRequest  []byte
+	lastResponse *PluginResponse
+}
+
+// NewPluginTemplate returns the template generated by the executable at path
+func NewPluginTemplate(name, path string) *PluginTemplate {
+	return &PluginTemplate{name: name, path: path}
+}
+
+// plugins holds the plugins found on PATH, looked up once
+var plugins = sync.OnceValue(func() []Template {
+	return discoverPlugins(filepath.SplitList(os.Getenv("PATH")))
+})
+
+// discoverPlugins returns a template for every plugin executable in dirs.
+// As with commands, a plugin earlier in dirs hides those of the same name.
+func discoverPlugins(dirs []string) []Template {
+	var found []Template
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), PluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, ".exe")
+			}
+			if !ok || name == "" || seen[name] || entry.IsDir() {
+				continue
+			}
+			path, err := exec.LookPath(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			seen[name] = true
+			found = append(found, NewPluginTemplate(name, path))
+		}
+	}
+	return found
+}
+
+func (t *PluginTemplate) Name() string {
+	return t.name
+}
+
+// Description returns the description the plugin gives, or where it was
+// found if it cannot describe itself
+func (t *PluginTemplate) Description() string {
+	description, err := t.describe()
+	if err != nil || description.Description == "" {
+		return fmt.Sprintf("Template plugin %s", t.path)
+	}
+	return description.Description
+}
+
+// Path returns the path of the plugin executable
+func (t *PluginTemplate) Path() string {
+	return t.path
+}
+
+// Variables returns the variables the plugin declares
+func (t *PluginTemplate) Variables() []Variable {
+	description, err := t.describe()
+	if err != nil {
+		return nil
+	}
+	return description.Variables
+}
+
+func (t *PluginTemplate) GenerateFiles(projectName string) map[string]string {
+	files, err := t.Render(Params{ProjectName: projectName})
+	if err != nil {
+		return map[string]string{}
+	}
+	return files
+}
+
+// GetDependencies returns nothing, as plugins only return their
+// dependencies together with the files; see RenderDependencies
+func (t *PluginTemplate) GetDependencies() []string {
+	return nil
+}
+
+// Render validates the variables and runs the plugin to generate the project
+func (t *PluginTemplate) Render(params Params) (map[string]string, error) {
+	response, err := t.generate(params)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(response.Files))
+	for filePath, content := range response.Files {
+		files[filePath] = content
+	}
+	return files, nil
+}
+
+// RenderDependencies runs the plugin to generate the project and returns
+// the dependencies it lists
+func (t *PluginTemplate) RenderDependencies(params Params) ([]string, error) {
+	response, err := t.generate(params)
+	if err != nil {
+		return nil, err
+	}
+	return response.Dependencies, nil
+}
+
+// describe asks the plugin for its description and variables once
+func (t *PluginTemplate) describe() (PluginDescription, error) {
+	t.describeOnce.Do(func() {
+		t.describeErr = t.call(PluginRequest{Action: ActionDescribe}, &t.description)
+		if t.describeErr == nil {
+			manifest := Manifest{Variables: t.description.Variables}
+			if err := manifest.validate(); err != nil {
+				t.describeErr = fmt.Errorf("template plugin %s declares invalid variables: %w", t.name, err)
+			}
+			t.description.Variables = manifest.Variables
+		}
+	})
+	return t.description, t.describeErr
+}
+
+// generate runs the plugin for the project and checks its response
+func (t *PluginTemplate) generate(params Params) (*PluginResponse, error) {
+	description, err := t.describe()
+	if err != nil {
+		return nil, err
+	}
+	vars, err := ResolveVariables(description.Variables, params.Vars)
+	if err != nil {
+		return nil, err
+	}
+	request := PluginRequest{
+		Action:      ActionGenerate,
+		ProjectName: params.ProjectName,
+		ModulePath:  params.Module(),
+		Variables:   vars,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if t.lastResponse != nil && bytes.Equal(key, t.lastRequest) {
+		return t.lastResponse, nil
+	}
+
+	var response PluginResponse
+	if err := t.call(request, &response); err != nil {
+		return nil, err
+	}
+	for filePath := range response.Files {
+		if !fs.ValidPath(filePath) || filePath == "." {
+			return nil, fmt.Errorf("template plugin %s generated invalid file path %q", t.name, filePath)
+		}
+	}
+	for _, dependency := range response.Dependencies {
+		if _, err := ParseDependency(dependency); err != nil {
+			return nil, fmt.Errorf("template plugin %s: %w", t.name, err)
+		}
+	}
+
+	t.lastRequest, t.lastResponse = key, &response
+	return &response, nil
+}
+
+// call runs the plugin with the request on standard input and decodes its
+// standard output into response
+func (t *PluginTemplate) call(request PluginRequest, response any) error {
+	request.Protocol = PluginProtocol
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Processes the plugin started may keep its output open after it is killed
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("template plugin %s did not %s within %s", t.name, request.Action, pluginTimeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("template plugin %s failed to %s: %s", t.name, request.Action, message)
+		}
+		return fmt.Errorf("template plugin %s failed to %s: %w", t.name, request.Action, err)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf("template plugin %s returned invalid JSON to %s: %w", t.name, request.Action, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPluginProcess is not a test: it acts as the plugin when the test
+// binary is run by the script written in TestPlugin
+func TestPluginProcess(t *testing.T) {
+	if os.Getenv("SMALL_GO_TEST_PLUGIN") == "" {
+		return
+	}
+
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var response any
+	switch request.Action {
+	case ActionDescribe:
+		response = PluginDescription{
+			Description: "Service generated by a plugin",
+			Variables:   []Variable{{Name: "metrics", Type: VarBool}},
+		}
+	case ActionGenerate:
+		if request.ProjectName == "fail" {
+			fmt.Fprintln(os.Stderr, "cannot generate fail")
+			os.Exit(1)
+		}
+		if request.ProjectName == "hang" {
+			time.Sleep(time.Minute)
+		}
+		response = PluginResponse{
+			Files: map[string]string{
+				"main.go": fmt.Sprintf("package main // %s metrics=%v\n", request.ModulePath, request.Variables["metrics"]),
+			},
+			Dependencies: []string{"go.uber.org/zap@v1.27.0"},
+		}
+	}
+	json.NewEncoder(os.Stdout).Encode(response)
+	os.Exit(0)
+}
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin script needs a Unix shell")
+	}
+
+	dir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nSMALL_GO_TEST_PLUGIN=1 exec %q -test.run='^TestPluginProcess$'\n", os.Args[0])
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"svc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Not executable, so not a plugin
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"notes"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	found := discoverPlugins([]string{dir, t.TempDir()})
+	if len(found) != 1 || found[0].Name() != "svc" {
+		t.Fatalf("discovered %d plugins, want svc", len(found))
+	}
+	plugin := found[0]
+	if plugin.Description() != "Service generated by a plugin" {
+		t.Errorf("description = %q", plugin.Description())
+	}
+
+	params := Params{ProjectName: "svc", ModulePath: "example.com/svc", Vars: map[string]string{"metrics": "true"}}
+	files, err := RenderFiles(plugin, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := files["main.go"]; got != "package main // example.com/svc metrics=true\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	dependencies, err := GetDependencies(plugin, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dependencies, []Dependency{{Path: "go.uber.org/zap", Version: "v1.27.0"}}) {
+		t.Errorf("dependencies = %v", dependencies)
+	}
+
+	if _, err := RenderFiles(plugin, Params{ProjectName: "svc", Vars: map[string]string{"metrics": "maybe"}}); err == nil {
+		t.Error("invalid variables should be rejected before running the plugin")
+	}
+	if _, err := RenderFiles(plugin, Params{ProjectName: "fail"}); err == nil || !strings.Contains(err.Error(), "cannot generate fail") {
+		t.Errorf("plugin failure = %v, want its standard error", err)
+	}
+
+	// A plugin that hangs is killed
+	defer func(timeout time.Duration) { pluginTimeout = timeout }(pluginTimeout)
+	pluginTimeout = 200 * time.Millisecond
+	start := time.Now()
+	if _, err := RenderFiles(plugin, Params{ProjectName: "hang"}); err == nil || !strings.Contains(err.Error(), "did not generate within") {
+		t.Errorf("hanging plugin = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("hanging plugin was stopped after %s", elapsed)
+	}
+}
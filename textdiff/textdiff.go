@@ -0,0 +1,325 @@
+// Package textdiff compares and merges text files line by line.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict markers written around the two sides of a conflicting change
+const (
+	MarkerOurs   = "<<<<<<< yours"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> template"
+)
+
+// Lines splits text into lines, keeping the trailing newline of each line
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// match pairs a line of one file with an equal line of another
+type match struct {
+	a, b int
+}
+
+// matches returns the longest common subsequence of a and b as pairs of
+// line indexes in increasing order, using Myers' O(ND) algorithm
+func matches(a, b []string) []match {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d, offset)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the Myers trace back from the end to collect the matched lines
+func backtrack(trace [][]int, a, b []string, d, offset int) []match {
+	var result []match
+	x, y := len(a), len(b)
+
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			result = append(result, match{x, y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		result = append(result, match{x, y})
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs.
+// Overlapping changes that differ are written between conflict markers,
+// and the number of such conflicts is returned.
+func Merge3(base, ours, theirs string) (string, int) {
+	o, a, b := Lines(base), Lines(ours), Lines(theirs)
+
+	inA := make([]int, len(o))
+	inB := make([]int, len(o))
+	for i := range o {
+		inA[i], inB[i] = -1, -1
+	}
+	for _, m := range matches(o, a) {
+		inA[m.a] = m.b
+	}
+	for _, m := range matches(o, b) {
+		inB[m.a] = m.b
+	}
+
+	var out strings.Builder
+	conflicts := 0
+	io, ia, ib := 0, 0, 0
+
+	for {
+		// Find the next base line kept unchanged by both sides
+		next := -1
+		for j := io; j < len(o); j++ {
+			if inA[j] >= 0 && inB[j] >= 0 {
+				next = j
+				break
+			}
+		}
+
+		endO, endA, endB := len(o), len(a), len(b)
+		if next >= 0 {
+			endO, endA, endB = next, inA[next], inB[next]
+		}
+
+		if io < endO || ia < endA || ib < endB {
+			if mergeChunk(&out, o[io:endO], a[ia:endA], b[ib:endB]) {
+				conflicts++
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		out.WriteString(o[next])
+		io, ia, ib = next+1, inA[next]+1, inB[next]+1
+	}
+
+	return out.String(), conflicts
+}
+
+// Merge2 combines two versions of a file without a common ancestor, keeping
+// the lines they share and marking every difference as a conflict
+func Merge2(ours, theirs string) (string, int) {
+	a, b := Lines(ours), Lines(theirs)
+
+	var out strings.Builder
+	conflicts := 0
+	ia, ib := 0, 0
+	for _, m := range append(matches(a, b), match{len(a), len(b)}) {
+		if ia < m.a || ib < m.b {
+			writeConflict(&out, a[ia:m.a], b[ib:m.b])
+			conflicts++
+		}
+		if m.a < len(a) {
+			out.WriteString(a[m.a])
+		}
+		ia, ib = m.a+1, m.b+1
+	}
+
+	return out.String(), conflicts
+}
+
+// mergeChunk writes the merge of one unstable chunk and reports whether it conflicts
+func mergeChunk(out *strings.Builder, base, ours, theirs []string) bool {
+	switch {
+	case equal(ours, base):
+		writeLines(out, theirs)
+	case equal(theirs, base), equal(ours, theirs):
+		writeLines(out, ours)
+	default:
+		writeConflict(out, ours, theirs)
+		return true
+	}
+	return false
+}
+
+func writeConflict(out *strings.Builder, ours, theirs []string) {
+	out.WriteString(MarkerOurs + "\n")
+	writeTerminated(out, ours)
+	out.WriteString(MarkerSep + "\n")
+	writeTerminated(out, theirs)
+	out.WriteString(MarkerTheirs + "\n")
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// writeTerminated writes lines, making sure the last one ends with a newline
+func writeTerminated(out *strings.Builder, lines []string) {
+	writeLines(out, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteString("\n")
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// op is one line of an edit script: kept, removed from a or added from b
+type op struct {
+	kind byte
+	line string
+	// a and b count the lines of each file before this one
+	a, b int
+}
+
+// script returns the edit script turning a into b
+func script(a, b []string) []op {
+	var ops []op
+	ia, ib := 0, 0
+	for _, m := range append(matches(a, b), match{len(a), len(b)}) {
+		for ; ia < m.a; ia++ {
+			ops = append(ops, op{'-', a[ia], ia, ib})
+		}
+		for ; ib < m.b; ib++ {
+			ops = append(ops, op{'+', b[ib], ia, ib})
+		}
+		if m.a < len(a) {
+			ops = append(ops, op{' ', a[m.a], ia, ib})
+		}
+		ia, ib = m.a+1, m.b+1
+	}
+	return ops
+}
+
+// Unified returns the differences between a and b in unified diff format
+// with the given number of context lines, or an empty string if they are equal
+func Unified(nameA, nameB, a, b string, context int) string {
+	ops := script(Lines(a), Lines(b))
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while the following
+		// change is close enough to share context: at most 2*context
+		// unchanged lines lie between them
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops) && i <= last+2*context+1; i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			}
+		}
+
+		from := max(first-context, start)
+		to := min(last+context+1, len(ops))
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		writeHunk(&out, ops[from:to])
+		start = to
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []op) {
+	lenA, lenB := 0, 0
+	for _, o := range ops {
+		if o.kind != '+' {
+			lenA++
+		}
+		if o.kind != '-' {
+			lenB++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(ops[0].a, lenA), hunkRange(ops[0].b, lenB))
+
+	for _, o := range ops {
+		out.WriteByte(o.kind)
+		out.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the line range of a hunk; empty ranges name the line before them
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
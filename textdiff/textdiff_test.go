@@ -0,0 +1,178 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+// conflict returns the text Merge3 writes for a conflict between ours and theirs
+func conflict(ours, theirs string) string {
+	return MarkerOurs + "\n" + ours + MarkerSep + "\n" + theirs + MarkerTheirs + "\n"
+}
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+
+	for _, tc := range []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name: "no changes",
+			base: base, ours: base, theirs: base,
+			want: base,
+		},
+		{
+			name:   "changes to different lines",
+			base:   base,
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: "a\nb\nc\nD\ne\n",
+			want:   "a\nB\nc\nD\ne\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   base,
+			ours:   base,
+			theirs: "a\nb\nc\nd\ne\nf\n",
+			want:   "a\nb\nc\nd\ne\nf\n",
+		},
+		{
+			name:   "the same change on both sides",
+			base:   base,
+			ours:   "a\nb\nX\nd\ne\n",
+			theirs: "a\nb\nX\nd\ne\n",
+			want:   "a\nb\nX\nd\ne\n",
+		},
+		{
+			name:      "conflicting changes",
+			base:      base,
+			ours:      "a\nb\nours\nd\ne\n",
+			theirs:    "a\nb\ntheirs\nd\ne\n",
+			want:      "a\nb\n" + conflict("ours\n", "theirs\n") + "d\ne\n",
+			conflicts: 1,
+		},
+		{
+			name:      "two conflicting hunks",
+			base:      base,
+			ours:      "A1\nb\nc\nd\nE1\n",
+			theirs:    "A2\nb\nc\nd\nE2\n",
+			want:      conflict("A1\n", "A2\n") + "b\nc\nd\n" + conflict("E1\n", "E2\n"),
+			conflicts: 2,
+		},
+		{
+			name:   "edits at the start and end of the file",
+			base:   base,
+			ours:   "start\na\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\ne\nend\n",
+			want:   "start\na\nb\nc\nd\ne\nend\n",
+		},
+		{
+			name:   "deleted on one side",
+			base:   base,
+			ours:   "a\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "a\nc\nd\nE\n",
+		},
+		{
+			name:   "missing trailing newline kept",
+			base:   "a\nb\nc",
+			ours:   "A\nb\nc",
+			theirs: "a\nb\nc",
+			want:   "A\nb\nc",
+		},
+		{
+			name:   "trailing newline added by theirs",
+			base:   "a\nb\nc",
+			ours:   "A\nb\nc",
+			theirs: "a\nb\nc\n",
+			want:   "A\nb\nc\n",
+		},
+		{
+			name:      "conflict on a last line without newline",
+			base:      "a\nb",
+			ours:      "a\nours",
+			theirs:    "a\ntheirs",
+			want:      "a\n" + conflict("ours\n", "theirs\n"),
+			conflicts: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, conflicts := Merge3(tc.base, tc.ours, tc.theirs)
+			if got != tc.want {
+				t.Errorf("merged:\n%s\nwant:\n%s", got, tc.want)
+			}
+			if conflicts != tc.conflicts {
+				t.Errorf("conflicts = %d, want %d", conflicts, tc.conflicts)
+			}
+		})
+	}
+}
+
+func TestMerge2(t *testing.T) {
+	got, conflicts := Merge2("a\nours\nc\n", "a\ntheirs\nc\nd\n")
+	want := "a\n" + conflict("ours\n", "theirs\n") + "c\n" + conflict("", "d\n")
+	if got != want || conflicts != 2 {
+		t.Errorf("Merge2 = %q, %d, want %q, 2", got, conflicts, want)
+	}
+
+	if got, conflicts := Merge2("a\nb\n", "a\nb\n"); got != "a\nb\n" || conflicts != 0 {
+		t.Errorf("merging equal files = %q, %d", got, conflicts)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n", b: "a\nb\n", context: 3,
+			want: "",
+		},
+		{
+			name: "one change with context",
+			a:    "1\n2\n3\n4\n5\n", b: "1\n2\nthree\n4\n5\n", context: 1,
+			want: "--- a\n+++ b\n@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n",
+		},
+		{
+			name: "distant changes in separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n", b: "one\n2\n3\n4\n5\n6\nseven\n", context: 1,
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -6,2 +6,2 @@\n 6\n-7\n+seven\n",
+		},
+		{
+			name: "changes 2*context lines apart share a hunk",
+			a:    "1\n2\n3\n4\n", b: "one\n2\n3\nfour\n", context: 1,
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n",
+		},
+		{
+			name: "new file",
+			a:    "", b: "a\n", context: 3,
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "missing trailing newline",
+			a:    "a\nb", b: "a\nb\n", context: 0,
+			want: "--- a\n+++ b\n@@ -2 +2 @@\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Unified("a", "b", tc.a, tc.b, tc.context); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	if got := Lines("a\nb"); strings.Join(got, "|") != "a\n|b" {
+		t.Errorf("Lines = %q", got)
+	}
+	if got := Lines(""); got != nil {
+		t.Errorf("Lines of empty text = %q", got)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dawit-go/small-go/lockfile"
+	"github.com/dawit-go/small-go/scaffold"
+	"github.com/dawit-go/small-go/source"
+	"github.com/dawit-go/small-go/templates"
+	"github.com/dawit-go/small-go/textdiff"
+	"github.com/dawit-go/small-go/wiring"
+)
+
+// upgradeSummary counts what an upgrade did with the changed files
+type upgradeSummary struct {
+	merged, skipped, conflicted int
+}
+
+// upgradeProject merges the changes between the template output a project
+// was generated with and the output of the current template into the project
+func upgradeProject(projectDir string) error {
+	lock, err := lockfile.Read(projectDir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s has no %s; only projects generated by this version of small-go or later can be upgraded", projectDir, lockfile.FileName)
+	}
+	if err != nil {
+		return err
+	}
+
+	template, err := lockedTemplate(lock)
+	if err != nil {
+		return err
+	}
+	if template, err = templates.WithFeatures(template, lock.Features); err != nil {
+		return err
+	}
+
+	current, err := regenerate(projectDir, lock, template)
+	if err != nil {
+		return err
+	}
+
+	paths := make(map[string]bool)
+	for filePath := range lock.Files {
+		paths[filePath] = true
+	}
+	for filePath := range current {
+		paths[filePath] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for filePath := range paths {
+		sorted = append(sorted, filePath)
+	}
+	sort.Strings(sorted)
+
+	var summary upgradeSummary
+	for _, filePath := range sorted {
+		if err := upgradeFile(projectDir, lock, filePath, current, &summary); err != nil {
+			return err
+		}
+	}
+
+	lock.Version = smallGoVersion()
+	lock.Files = make(map[string]string, len(current))
+	for filePath, content := range current {
+		lock.Record(filePath, []byte(content))
+	}
+	if err := lock.Write(projectDir); err != nil {
+		return fmt.Errorf("failed to update %s: %w", lockfile.FileName, err)
+	}
+	if err := lockfile.WriteBase(projectDir, current); err != nil {
+		return fmt.Errorf("failed to update %s: %w", lockfile.BaseDir, err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Upgraded to small-go %s: %d merged, %d skipped, %d conflicted\n",
+		lock.Version, summary.merged, summary.skipped, summary.conflicted)
+
+	if summary.conflicted > 0 {
+		if lock.Vendor {
+			fmt.Println("Resolve the conflict markers, then run 'go mod tidy' and 'go mod vendor' to pick up new dependencies.")
+		} else {
+			fmt.Println("Resolve the conflict markers, then run 'go mod tidy' to pick up new dependencies.")
+		}
+		return nil
+	}
+
+	if err := requirePinned(projectDir, template, lockedParams(lock)); err != nil {
+		return err
+	}
+	if err := runGoModTidy(context.Background(), projectDir); err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
+	if lock.Vendor {
+		if err := runGoModVendor(context.Background(), projectDir); err != nil {
+			return fmt.Errorf("failed to run go mod vendor: %w", err)
+		}
+	}
+	return nil
+}
+
+// requirePinned adds the dependencies the template pins to the project's
+// go.mod, leaving modules the project already requires at their version
+func requirePinned(projectDir string, template templates.Template, params templates.Params) error {
+	dependencies, err := templates.GetDependencies(template, params)
+	if err != nil {
+		return err
+	}
+
+	goModPath := filepath.Join(projectDir, "go.mod")
+	goMod, err := os.ReadFile(goModPath)
+	if err != nil {
+		return err
+	}
+	updated, err := scaffold.AddRequirements(goMod, dependencies)
+	if err != nil {
+		return fmt.Errorf("failed to update go.mod: %w", err)
+	}
+	return os.WriteFile(goModPath, updated, 0644)
+}
+
+// lockedTemplate returns the template recorded in the lockfile, loading it
+// again from its directory or source if it was not loaded with --template-dir
+// or --template-source
+func lockedTemplate(lock *lockfile.Lockfile) (templates.Template, error) {
+	if template := templates.GetTemplateByName(lock.Template.Name); template != nil {
+		return template, nil
+	}
+	if lock.Template.Source == "" {
+		return nil, fmt.Errorf("project was generated from template %s, which is not available", lock.Template.Name)
+	}
+
+	var template *templates.DirTemplate
+	var err error
+	if source.IsSource(lock.Template.Source) {
+		template, err = loadTemplateSource(lock.Template.Source)
+	} else {
+		template, err = templates.LoadDirTemplate(lock.Template.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if template.Name() != lock.Template.Name {
+		return nil, fmt.Errorf("%s now holds template %s instead of %s", lock.Template.Source, template.Name(), lock.Template.Name)
+	}
+	return template, nil
+}
+
+// lockedParams returns the inputs the project was generated with
+func lockedParams(lock *lockfile.Lockfile) templates.Params {
+	return templates.Params{ProjectName: lock.Project.Name, ModulePath: lock.Project.Module, Vars: lock.Variables}
+}
+
+// regenerate renders what the current template generates for the project,
+// including the entities added since and their registrations
+func regenerate(projectDir string, lock *lockfile.Lockfile, template templates.Template) (map[string]string, error) {
+	files, err := scaffold.Render(template, lockedParams(lock))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate files: %w", err)
+	}
+	if len(lock.Entities) == 0 {
+		return files, nil
+	}
+
+	generator, ok := templates.GetEntityGenerator(template)
+	if !ok {
+		return nil, fmt.Errorf("template %s no longer supports entities", template.Name())
+	}
+	modulePath, err := readModulePath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, locked := range lock.Entities {
+		entity, err := templates.ParseEntity(locked.Name, locked.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", locked.Name, err)
+		}
+		entityFiles, edits, err := scaffold.RenderEntity(generator, modulePath, entity)
+		if err != nil {
+			return nil, err
+		}
+		for filePath, content := range entityFiles {
+			files[filePath] = content
+		}
+
+		contents := make(map[string][]byte, len(edits))
+		for _, edit := range edits {
+			if content, ok := files[edit.File]; ok {
+				contents[edit.File] = []byte(content)
+			}
+		}
+		changes, err := wiring.PlanFiles(contents, edits)
+		if err != nil {
+			return nil, fmt.Errorf("cannot register %s in the current template:\n%w", entity.Name, err)
+		}
+		for filePath, content := range changes {
+			files[filePath] = string(content)
+		}
+	}
+
+	return files, nil
+}
+
+// upgradeFile brings one file in line with the current template output
+func upgradeFile(projectDir string, lock *lockfile.Lockfile, filePath string, current map[string]string, summary *upgradeSummary) error {
+	fullPath := filepath.Join(projectDir, filePath)
+	theirs, generated := current[filePath]
+	_, recorded := lock.Files[filePath]
+
+	content, err := os.ReadFile(fullPath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	ours := string(content)
+
+	base, hasBase := upgradeBase(projectDir, lock, filePath, ours)
+
+	switch {
+	case !exists && !generated:
+		return nil
+
+	case !exists && recorded:
+		fmt.Printf("  skipped %s (deleted in the project)\n", filePath)
+		summary.skipped++
+		return nil
+
+	case !exists:
+		fmt.Printf("  added %s\n", filePath)
+		summary.merged++
+		return writeFile(fullPath, theirs)
+
+	case !generated:
+		if hasBase && ours == base {
+			fmt.Printf("  removed %s\n", filePath)
+			summary.merged++
+			return os.Remove(fullPath)
+		}
+		fmt.Printf("  skipped %s (removed from the template but modified in the project)\n", filePath)
+		summary.skipped++
+		return nil
+
+	case ours == theirs, hasBase && base == theirs:
+		// Already up to date, or the template did not change this file
+		return nil
+	}
+
+	var merged string
+	var conflicts int
+	if hasBase {
+		merged, conflicts = textdiff.Merge3(base, ours, theirs)
+	} else {
+		merged, conflicts = textdiff.Merge2(ours, theirs)
+	}
+
+	if err := writeFile(fullPath, merged); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	if conflicts > 0 {
+		fmt.Printf("  conflict %s (%d)\n", filePath, conflicts)
+		summary.conflicted++
+		return nil
+	}
+	fmt.Printf("  merged %s\n", filePath)
+	summary.merged++
+	return nil
+}
+
+// upgradeBase returns the content small-go originally generated for a file.
+// Without a base copy, a file whose hash still matches the lockfile is its
+// own base.
+func upgradeBase(projectDir string, lock *lockfile.Lockfile, filePath, ours string) (string, bool) {
+	if base, err := lockfile.ReadBase(projectDir, filePath); err == nil {
+		return base, true
+	}
+	if recorded, ok := lock.Files[filePath]; ok && lockfile.Hash([]byte(ours)) == recorded {
+		return ours, true
+	}
+	return "", false
+}
@@ -0,0 +1,382 @@
+// Package verify generates projects from templates and checks that they
+// build, vet and pass their tests, resolving dependencies from the local
+// module cache only.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dawit-go/small-go/scaffold"
+	"github.com/dawit-go/small-go/templates"
+	"github.com/dawit-go/small-go/wiring"
+)
+
+// maxCombinations bounds the variable combinations checked per template
+const maxCombinations = 64
+
+// The entity added to templates that support entities, using every kind of field
+const (
+	sampleEntityName   = "OrderItem"
+	sampleEntityFields = "quantity:int,price:float64,note:string,placedAt:time.Time,tags:[]string,gift:bool"
+)
+
+// Steps a failure can be reported for
+const (
+	StepDependencies = "resolve dependencies"
+	StepGenerate     = "generate"
+	StepAddEntity    = "add entity"
+	StepBuild        = "go build"
+	StepVet          = "go vet"
+	StepTest         = "go test"
+)
+
+// Case is one project to generate and check
+type Case struct {
+	Name     string
+	Template templates.Template
+	Params   templates.Params
+	// Entity is added to the project after generating it, when set
+	Entity *templates.Entity
+}
+
+// Failure is a problem found while checking a case
+type Failure struct {
+	Step string
+	// File and Line locate the problem in the generated project, if known
+	File    string
+	Line    int
+	Message string
+}
+
+func (f Failure) String() string {
+	switch {
+	case f.File != "" && f.Line > 0:
+		return fmt.Sprintf("%s: %s:%d: %s", f.Step, f.File, f.Line, f.Message)
+	case f.File != "":
+		return fmt.Sprintf("%s: %s: %s", f.Step, f.File, f.Message)
+	default:
+		return fmt.Sprintf("%s: %s", f.Step, f.Message)
+	}
+}
+
+// Result is the outcome of checking a case
+type Result struct {
+	Case     Case
+	Dir      string
+	Failures []Failure
+}
+
+// OK reports whether every check passed
+func (r Result) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// Files returns the files with failures in sorted order; failures that are
+// not tied to a file are listed under the empty name
+func (r Result) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, failure := range r.Failures {
+		if !seen[failure.File] {
+			seen[failure.File] = true
+			files = append(files, failure.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Cases returns a case for every combination of the variables of each
+// template, one more with an entity added for templates that support it,
+// and cases covering every feature that can be added to the template
+func Cases(list []templates.Template) []Case {
+	var cases []Case
+	for _, template := range list {
+		for _, vars := range combinations(templates.GetVariables(template)) {
+			name := template.Name()
+			if len(vars) > 0 {
+				name += " " + describe(vars)
+			}
+			cases = append(cases, newCase(name, template, vars))
+		}
+
+		if _, ok := templates.GetEntityGenerator(template); ok {
+			entity, err := templates.ParseEntity(sampleEntityName, sampleEntityFields)
+			if err != nil {
+				panic(err)
+			}
+			c := newCase(template.Name()+" +entity", template, nil)
+			c.Entity = &entity
+			cases = append(cases, c)
+		}
+
+		for _, features := range featureSets(template) {
+			composed, err := templates.WithFeatures(template, features)
+			if err != nil {
+				panic(err)
+			}
+			cases = append(cases, newCase(template.Name()+" +"+strings.Join(features, "+"), composed, nil))
+		}
+	}
+	return cases
+}
+
+// featureSets groups the features that can be added to a template into as
+// few sets as possible, keeping conflicting features apart
+func featureSets(template templates.Template) [][]string {
+	var sets [][]string
+	for _, feature := range templates.GetAvailableFeatures() {
+		if _, err := templates.WithFeatures(template, []string{feature.Name()}); err != nil {
+			continue
+		}
+
+		i := slices.IndexFunc(sets, func(set []string) bool {
+			_, err := templates.WithFeatures(template, append(slices.Clone(set), feature.Name()))
+			return err == nil
+		})
+		if i < 0 {
+			sets = append(sets, nil)
+			i = len(sets) - 1
+		}
+		sets[i] = append(sets[i], feature.Name())
+	}
+	return sets
+}
+
+// newCase generates the project under a module path that differs from its
+// name, as with small-go new --module
+func newCase(name string, template templates.Template, vars map[string]string) Case {
+	projectName := "verify-" + template.Name()
+	return Case{
+		Name:     name,
+		Template: template,
+		Params: templates.Params{
+			ProjectName: projectName,
+			ModulePath:  "example.com/" + projectName,
+			Vars:        vars,
+		},
+	}
+}
+
+// combinations returns every combination of the values of bool and enum
+// variables; int variables take their default or 0, and string variables
+// their default or "example"
+func combinations(variables []templates.Variable) []map[string]string {
+	result := []map[string]string{{}}
+	for _, v := range variables {
+		var values []string
+		switch v.Type {
+		case templates.VarBool:
+			values = []string{"true", "false"}
+		case templates.VarEnum:
+			values = v.Options
+		case templates.VarInt:
+			values = []string{v.Default}
+			if v.Default == "" {
+				values = []string{"0"}
+			}
+		default:
+			values = []string{v.Default}
+			if v.Default == "" {
+				values = []string{"example"}
+			}
+		}
+
+		var next []map[string]string
+		for _, combination := range result {
+			for _, value := range values {
+				vars := make(map[string]string, len(combination)+1)
+				for name, existing := range combination {
+					vars[name] = existing
+				}
+				vars[v.Name] = value
+				next = append(next, vars)
+			}
+		}
+		result = next
+		if len(result) > maxCombinations {
+			result = result[:maxCombinations]
+		}
+	}
+	return result
+}
+
+// describe formats variables as sorted name=value pairs
+func describe(vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + vars[name]
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Options configures a Verifier
+type Options struct {
+	// Dir holds the generated projects; a temporary directory is used when empty
+	Dir string
+	// Keep leaves the generated projects in place after Close
+	Keep bool
+}
+
+// Verifier generates and checks cases
+type Verifier struct {
+	dir     string
+	cleanup bool
+	count   int
+}
+
+// New returns a Verifier
+func New(opts Options) (*Verifier, error) {
+	v := &Verifier{dir: opts.Dir}
+	if v.dir == "" {
+		var err error
+		if v.dir, err = os.MkdirTemp("", "small-go-verify-"); err != nil {
+			return nil, err
+		}
+		v.cleanup = !opts.Keep
+	}
+	return v, nil
+}
+
+// Dir returns the directory holding the generated projects
+func (v *Verifier) Dir() string {
+	return v.dir
+}
+
+// Close removes the temporary directory created by New, unless asked to keep it
+func (v *Verifier) Close() error {
+	if v.cleanup {
+		return os.RemoveAll(v.dir)
+	}
+	return nil
+}
+
+// Run generates a case and runs go build, go vet and go test on it,
+// stopping at the first step that fails
+func (v *Verifier) Run(ctx context.Context, c Case) Result {
+	v.count++
+	result := Result{Case: c, Dir: filepath.Join(v.dir, fmt.Sprintf("%02d-%s", v.count, c.Template.Name()))}
+
+	_, err := scaffold.Generate(ctx, scaffold.Options{
+		Template: c.Template,
+		Params:   c.Params,
+		Dir:      result.Dir,
+		Runner:   runner(),
+		Offline:  true,
+		Version:  "verify",
+	})
+	var missing *scaffold.MissingModulesError
+	if errors.As(err, &missing) {
+		result.Failures = append(result.Failures, Failure{Step: StepDependencies, Message: missing.Error()})
+		return result
+	}
+	if err != nil {
+		result.Failures = append(result.Failures, Failure{Step: StepGenerate, Message: err.Error()})
+		return result
+	}
+
+	if c.Entity != nil {
+		if err := addEntity(result.Dir, c); err != nil {
+			result.Failures = append(result.Failures, Failure{Step: StepAddEntity, Message: err.Error()})
+			return result
+		}
+	}
+
+	for _, step := range []struct {
+		name string
+		args []string
+	}{
+		{StepBuild, []string{"build", "./..."}},
+		{StepVet, []string{"vet", "./..."}},
+		{StepTest, []string{"test", "./..."}},
+	} {
+		output, err := runGo(ctx, result.Dir, step.args...)
+		if err == nil {
+			continue
+		}
+		result.Failures = parseOutput(step.name, output)
+		if len(result.Failures) == 0 {
+			result.Failures = []Failure{{Step: step.name, Message: err.Error()}}
+		}
+		break
+	}
+
+	return result
+}
+
+// runner runs the go commands of scaffold.Generate offline
+func runner() scaffold.Runner {
+	return scaffold.RunnerFunc(func(ctx context.Context, dir, name string, args ...string) error {
+		output, err := runGo(ctx, dir, args...)
+		if err != nil {
+			return fmt.Errorf("%w\n%s", err, output)
+		}
+		return nil
+	})
+}
+
+// addEntity adds the case's entity to a generated project and registers it
+func addEntity(dir string, c Case) error {
+	generator, ok := templates.GetEntityGenerator(c.Template)
+	if !ok {
+		return fmt.Errorf("template %s does not support adding entities", c.Template.Name())
+	}
+
+	files, edits, err := scaffold.RenderEntity(generator, c.Params.Module(), *c.Entity)
+	if err != nil {
+		return err
+	}
+	for filePath, content := range files {
+		if err := scaffold.DirWriter(dir).WriteFile(filePath, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	_, err = wiring.Apply(dir, edits)
+	return err
+}
+
+// runGo runs the go command offline and returns its combined output
+func runGo(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), scaffold.OfflineEnv...)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimRight(string(output), "\n"), err
+}
+
+// fileLine matches the file:line:column: message lines of go build and go vet
+var fileLine = regexp.MustCompile(`^(?:vet: )?(?:\./)?([^\s:]+\.go):(\d+)(?::\d+)?: (.*)$`)
+
+// parseOutput turns the output of a failing go command into failures,
+// attributing each message to its file where possible
+func parseOutput(step, output string) []Failure {
+	var failures []Failure
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := fileLine.FindStringSubmatch(line); m != nil {
+			lineNumber, _ := strconv.Atoi(m[2])
+			failures = append(failures, Failure{Step: step, File: m[1], Line: lineNumber, Message: m[3]})
+			continue
+		}
+		failures = append(failures, Failure{Step: step, Message: strings.TrimSpace(line)})
+	}
+	return failures
+}
@@ -0,0 +1,103 @@
+package verify
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dawit-go/small-go/templates"
+)
+
+// TestTemplates generates every built-in template combination and checks
+// that it builds, vets and passes its tests
+func TestTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates and builds projects")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	ctx := context.Background()
+	verifier, err := New(Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The built-in templates only: GetAvailableTemplates also runs the
+	// plugins found on PATH
+	builtin := []templates.Template{&templates.HexagonalTemplate{}, &templates.CleanTemplate{}}
+	for _, c := range Cases(builtin) {
+		t.Run(c.Name, func(t *testing.T) {
+			result := verifier.Run(ctx, c)
+			for _, failure := range result.Failures {
+				if failure.Step == StepDependencies {
+					t.Skipf("dependencies are not in the module cache: %s", failure.Message)
+				}
+				t.Error(failure)
+			}
+		})
+	}
+}
+
+func TestCasesCombineVariables(t *testing.T) {
+	template, err := templates.LoadFSTemplate("vars", "memory", fstest.MapFS{
+		"template.yaml": {Data: []byte(`
+variables:
+  - name: owner
+  - name: docker
+    type: bool
+  - name: db
+    type: enum
+    options: [none, postgres, mongo]
+`)},
+		"main.go.tmpl": {Data: []byte("package main\n\nfunc main() {}\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := Cases([]templates.Template{template})
+	if len(cases) != 6 {
+		t.Fatalf("got %d cases, want 6", len(cases))
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		if c.Params.Vars["owner"] != "example" {
+			t.Errorf("%s: owner = %q, want the sample value", c.Name, c.Params.Vars["owner"])
+		}
+		seen[c.Name] = true
+	}
+	for _, name := range []string{
+		"vars db=none docker=true owner=example",
+		"vars db=mongo docker=false owner=example",
+	} {
+		if !seen[name] {
+			t.Errorf("missing case %q", name)
+		}
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	output := `# example.com/verify-clean/internal/handler/rest/mapper
+internal/handler/rest/mapper/user_mapper.go:12:21: user.ID.Hex undefined (type string has no field or method Hex)
+vet: ./initiator/handler.go:7:2: "fmt" imported and not used
+FAIL	example.com/verify-clean [build failed]`
+
+	got := parseOutput(StepBuild, output)
+	want := []Failure{
+		{Step: StepBuild, File: "internal/handler/rest/mapper/user_mapper.go", Line: 12, Message: "user.ID.Hex undefined (type string has no field or method Hex)"},
+		{Step: StepBuild, File: "initiator/handler.go", Line: 7, Message: `"fmt" imported and not used`},
+		{Step: StepBuild, Message: "FAIL\texample.com/verify-clean [build failed]"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d failures, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("failure %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dawit-go/small-go/templates"
+	"github.com/dawit-go/small-go/verify"
+)
+
+// verifyTemplates generates every case of the named templates, or of all
+// available templates, and reports which ones fail to build, vet or test
+func verifyTemplates(names []string, keep bool) error {
+	list := templates.GetAvailableTemplates()
+	if len(names) > 0 {
+		list = nil
+		for _, name := range names {
+			template := templates.GetTemplateByName(name)
+			if template == nil {
+				return fmt.Errorf("unknown template: %s. Use 'small-go list' to see available templates", name)
+			}
+			list = append(list, template)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	verifier, err := verify.New(verify.Options{Keep: keep})
+	if err != nil {
+		return err
+	}
+	defer verifier.Close()
+
+	failed := 0
+	for _, c := range verify.Cases(list) {
+		fmt.Printf("%s ... ", c.Name)
+		result := verifier.Run(ctx, c)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if result.OK() {
+			fmt.Println("ok")
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL (%s)\n", result.Failures[0].Step)
+		for _, file := range result.Files() {
+			indent := "    "
+			if file != "" {
+				fmt.Printf("    %s\n", file)
+				indent = "        "
+			}
+			for _, failure := range result.Failures {
+				if failure.File != file {
+					continue
+				}
+				if failure.Line > 0 {
+					fmt.Printf("%s%d: %s\n", indent, failure.Line, failure.Message)
+				} else {
+					fmt.Printf("%s%s\n", indent, failure.Message)
+				}
+			}
+		}
+	}
+
+	if keep {
+		fmt.Printf("\nGenerated projects kept in %s\n", verifier.Dir())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d template combination(s) failed", failed)
+	}
+	return nil
+}
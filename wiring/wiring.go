@@ -0,0 +1,466 @@
+// Package wiring registers generated code in existing project files.
+//
+// Files are parsed with go/ast only to find where code belongs; the new code
+// is inserted as text at those positions so that the rest of the file,
+// including the user's own edits and formatting, stays byte for byte the same.
+// Every edit is idempotent: code that is already present is left alone.
+package wiring
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind identifies how an Edit changes an existing file
+type Kind int
+
+const (
+	// AddProvider adds Code to the fx.Provide call
+	AddProvider Kind = iota
+	// AddParam appends the parameter Code to function Func
+	AddParam
+	// AddCallArg appends the argument Code to calls of Call inside function Func
+	AddCallArg
+	// InsertStatements inserts Code before the last return statement of function Func
+	InsertStatements
+)
+
+// Edit is a change to an existing project file that registers generated code
+type Edit struct {
+	File string
+	Kind Kind
+	Func string
+	Call string
+	Code string
+}
+
+// String describes the edit as an instruction
+func (e Edit) String() string {
+	switch e.Kind {
+	case AddProvider:
+		return fmt.Sprintf("%s: add %s to fx.Provide", e.File, e.Code)
+	case AddParam:
+		return fmt.Sprintf("%s: add parameter %q to %s", e.File, e.Code, e.Func)
+	case AddCallArg:
+		return fmt.Sprintf("%s: pass %s to %s in %s", e.File, e.Code, e.Call, e.Func)
+	default:
+		return fmt.Sprintf("%s: add to %s before it returns:\n%s", e.File, e.Func, e.Code)
+	}
+}
+
+// AnchorError reports an edit that cannot be applied because the code it
+// hooks into is missing or was changed beyond recognition
+type AnchorError struct {
+	Edit   Edit
+	Reason string
+}
+
+func (e *AnchorError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s; apply this change by hand:\n", e.Edit.File, e.Reason)
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", e.Edit.File, e.Edit.File)
+
+	switch e.Edit.Kind {
+	case AddProvider:
+		fmt.Fprintf(&b, "@@ fx.Provide( @@\n+\t\t\t%s,\n", e.Edit.Code)
+	case AddParam:
+		fmt.Fprintf(&b, "@@ func %s( @@\n+\t%s,\n", e.Edit.Func, e.Edit.Code)
+	case AddCallArg:
+		fmt.Fprintf(&b, "@@ func %s: %s( @@\n+\t%s,\n", e.Edit.Func, e.Edit.Call, e.Edit.Code)
+	default:
+		fmt.Fprintf(&b, "@@ func %s: before return @@\n", e.Edit.Func)
+		for _, line := range strings.Split(strings.TrimRight(e.Edit.Code, "\n"), "\n") {
+			fmt.Fprintf(&b, "+%s\n", line)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Plan applies the edits in memory and returns the new content of every
+// file that changes. It fails without changing anything if any edit cannot
+// be applied.
+func Plan(dir string, edits []Edit) (map[string][]byte, error) {
+	return plan(edits, func(file string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, file))
+	})
+}
+
+// PlanFiles is like Plan but edits the file contents in files instead of
+// the files on disk
+func PlanFiles(files map[string][]byte, edits []Edit) (map[string][]byte, error) {
+	return plan(edits, func(file string) ([]byte, error) {
+		content, ok := files[file]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return content, nil
+	})
+}
+
+func plan(edits []Edit, read func(file string) ([]byte, error)) (map[string][]byte, error) {
+	var order []string
+	byFile := make(map[string][]Edit)
+	for _, edit := range edits {
+		if _, ok := byFile[edit.File]; !ok {
+			order = append(order, edit.File)
+		}
+		byFile[edit.File] = append(byFile[edit.File], edit)
+	}
+
+	changes := make(map[string][]byte)
+	var errs []error
+	for _, file := range order {
+		original, err := read(file)
+		if err != nil {
+			for _, edit := range byFile[file] {
+				errs = append(errs, &AnchorError{Edit: edit, Reason: "file not found"})
+			}
+			continue
+		}
+
+		updated, err := applyFile(file, original, byFile[file])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if string(updated) != string(original) {
+			changes[file] = updated
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return changes, nil
+}
+
+// Apply plans the edits and writes the changed files, returning their paths
+func Apply(dir string, edits []Edit) ([]string, error) {
+	changes, err := Plan(dir, edits)
+	if err != nil {
+		return nil, err
+	}
+	return Write(dir, changes)
+}
+
+// Write writes the file contents returned by Plan, returning their paths
+func Write(dir string, changes map[string][]byte) ([]string, error) {
+	files := make([]string, 0, len(changes))
+	for file := range changes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, changes[file], info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+
+	return files, nil
+}
+
+// applyFile applies the edits of a single file one after the other,
+// re-parsing the source in between so every edit sees the previous ones
+func applyFile(name string, src []byte, edits []Edit) ([]byte, error) {
+	var errs []error
+	for _, edit := range edits {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		e := &editor{fset: fset, file: file, src: src}
+		offset, text, err := e.locate(edit)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if offset < 0 {
+			// Already applied
+			continue
+		}
+		src = []byte(string(src[:offset]) + text + string(src[offset:]))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Never write a file that no longer parses
+	if _, err := parser.ParseFile(token.NewFileSet(), name, src, parser.ParseComments); err != nil {
+		return nil, fmt.Errorf("edits to %s produce invalid Go: %w", name, err)
+	}
+	return src, nil
+}
+
+// editor locates the insertion point of edits in a parsed file
+type editor struct {
+	fset *token.FileSet
+	file *ast.File
+	src  []byte
+}
+
+// locate returns where and what to insert for an edit, or a negative offset
+// if the edit is already present
+func (e *editor) locate(edit Edit) (int, string, error) {
+	switch edit.Kind {
+	case AddProvider:
+		return e.addProvider(edit)
+	case AddParam:
+		return e.addParam(edit)
+	case AddCallArg:
+		return e.addCallArg(edit)
+	case InsertStatements:
+		return e.insertStatements(edit)
+	default:
+		return 0, "", fmt.Errorf("unknown edit kind %d", edit.Kind)
+	}
+}
+
+func (e *editor) addProvider(edit Edit) (int, string, error) {
+	fxName := e.importName("go.uber.org/fx")
+	if fxName == "" {
+		return 0, "", &AnchorError{Edit: edit, Reason: "go.uber.org/fx is not imported"}
+	}
+
+	var provide *ast.CallExpr
+	ast.Inspect(e.file, func(n ast.Node) bool {
+		if provide != nil {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok && e.source(call.Fun) == fxName+".Provide" {
+			provide = call
+		}
+		return true
+	})
+	if provide == nil {
+		return 0, "", &AnchorError{Edit: edit, Reason: "cannot find the fx.Provide call"}
+	}
+
+	for _, arg := range provide.Args {
+		if e.source(arg) == edit.Code {
+			return -1, "", nil
+		}
+	}
+	offset, text := e.appendToList(provide.Lparen, provide.Rparen, exprEnds(provide.Args), edit.Code)
+	return offset, text, nil
+}
+
+func (e *editor) addParam(edit Edit) (int, string, error) {
+	fn := e.findFunc(edit.Func)
+	if fn == nil {
+		return 0, "", &AnchorError{Edit: edit, Reason: "cannot find func " + edit.Func}
+	}
+
+	name, typ, ok := strings.Cut(strings.TrimSpace(edit.Code), " ")
+	if !ok {
+		return 0, "", fmt.Errorf("invalid parameter %q", edit.Code)
+	}
+
+	params := fn.Type.Params
+	var ends []token.Pos
+	for _, field := range params.List {
+		for _, fieldName := range field.Names {
+			if fieldName.Name != name {
+				continue
+			}
+			if compact(e.source(field.Type)) != compact(typ) {
+				return 0, "", &AnchorError{
+					Edit:   edit,
+					Reason: fmt.Sprintf("%s already has a parameter %s of type %s", edit.Func, name, e.source(field.Type)),
+				}
+			}
+			return -1, "", nil
+		}
+		ends = append(ends, field.End())
+	}
+
+	offset, text := e.appendToList(params.Opening, params.Closing, ends, edit.Code)
+	return offset, text, nil
+}
+
+func (e *editor) addCallArg(edit Edit) (int, string, error) {
+	fn := e.findFunc(edit.Func)
+	if fn == nil || fn.Body == nil {
+		return 0, "", &AnchorError{Edit: edit, Reason: "cannot find func " + edit.Func}
+	}
+
+	var call *ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok && call == nil && e.source(c.Fun) == edit.Call {
+			call = c
+		}
+		return call == nil
+	})
+	if call == nil {
+		return 0, "", &AnchorError{Edit: edit, Reason: fmt.Sprintf("cannot find the call to %s in %s", edit.Call, edit.Func)}
+	}
+
+	for _, arg := range call.Args {
+		if e.source(arg) == edit.Code {
+			return -1, "", nil
+		}
+	}
+	offset, text := e.appendToList(call.Lparen, call.Rparen, exprEnds(call.Args), edit.Code)
+	return offset, text, nil
+}
+
+func (e *editor) insertStatements(edit Edit) (int, string, error) {
+	fn := e.findFunc(edit.Func)
+	if fn == nil || fn.Body == nil {
+		return 0, "", &AnchorError{Edit: edit, Reason: "cannot find func " + edit.Func}
+	}
+
+	body := e.source(fn.Body)
+	if strings.Contains(compact(stripComments(body)), compact(stripComments(edit.Code))) {
+		return -1, "", nil
+	}
+
+	var last *ast.ReturnStmt
+	for _, stmt := range fn.Body.List {
+		if ret, ok := stmt.(*ast.ReturnStmt); ok {
+			last = ret
+		}
+	}
+	if last == nil {
+		return 0, "", &AnchorError{Edit: edit, Reason: fmt.Sprintf("cannot find the return statement of %s", edit.Func)}
+	}
+
+	offset := e.offset(last.Pos())
+	lineStart := strings.LastIndexByte(string(e.src[:offset]), '\n') + 1
+	indent := string(e.src[lineStart:offset])
+
+	// The code is written for a function body indented with one tab
+	var text strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(edit.Code, "\n"), "\n") {
+		if line != "" {
+			text.WriteString(indent + strings.TrimPrefix(line, "\t"))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("\n")
+
+	return lineStart, text.String(), nil
+}
+
+// appendToList returns the insertion that appends code to a parenthesized,
+// comma-separated list, keeping one element per line if the list already does
+func (e *editor) appendToList(open, close token.Pos, ends []token.Pos, code string) (int, string) {
+	if len(ends) == 0 {
+		return e.offset(open) + 1, code
+	}
+
+	last := ends[len(ends)-1]
+	if e.fset.Position(close).Line > e.fset.Position(last).Line {
+		// The last element already ends with a comma; the new one goes on
+		// the next line so that a comment after the last one stays with it
+		return e.nextLine(last), e.lineIndent(last) + code + ",\n"
+	}
+	return e.offset(last), ", " + code
+}
+
+// nextLine returns the offset of the line after the one containing pos,
+// skipping to the end of a block comment that starts on that line
+func (e *editor) nextLine(pos token.Pos) int {
+	end := e.offset(pos)
+	line := e.fset.Position(pos).Line
+	for _, group := range e.file.Comments {
+		for _, comment := range group.List {
+			if e.fset.Position(comment.Pos()).Line == line && e.offset(comment.End()) > end {
+				end = e.offset(comment.End())
+			}
+		}
+	}
+	if i := strings.IndexByte(string(e.src[end:]), '\n'); i >= 0 {
+		return end + i + 1
+	}
+	return len(e.src)
+}
+
+// findFunc returns the top-level function with the given name
+func (e *editor) findFunc(name string) *ast.FuncDecl {
+	for _, decl := range e.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// importName returns the name a package is imported under, or "" if it is not imported
+func (e *editor) importName(path string) string {
+	for _, spec := range e.file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return path[strings.LastIndexByte(path, '/')+1:]
+	}
+	return ""
+}
+
+func (e *editor) offset(pos token.Pos) int {
+	return e.fset.Position(pos).Offset
+}
+
+// source returns the source text of a node
+func (e *editor) source(node ast.Node) string {
+	return string(e.src[e.offset(node.Pos()):e.offset(node.End())])
+}
+
+// lineIndent returns the leading whitespace of the line containing pos
+func (e *editor) lineIndent(pos token.Pos) string {
+	offset := e.offset(pos)
+	lineStart := strings.LastIndexByte(string(e.src[:offset]), '\n') + 1
+	line := string(e.src[lineStart:offset])
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+func exprEnds(exprs []ast.Expr) []token.Pos {
+	ends := make([]token.Pos, len(exprs))
+	for i, expr := range exprs {
+		ends[i] = expr.End()
+	}
+	return ends
+}
+
+// compact removes all whitespace so that code can be compared regardless of formatting
+func compact(code string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// stripComments drops line comments so that users can remove generated comments
+func stripComments(code string) string {
+	lines := strings.Split(code, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,148 @@
+package wiring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const mainFile = `package main
+
+import "go.uber.org/fx"
+
+func main() {
+	fx.New(
+		fx.Provide(
+			app.NewLogger,
+			app.NewServer, // serves HTTP
+		),
+	).Run()
+}
+`
+
+const routerFile = `package http
+
+func NewRouter(userService UserService) Handler {
+	r := newMux()
+	r.Handle("/users", userService)
+
+	return r
+}
+
+func NewHandler(userService UserService) Handler {
+	return NewRouter(userService)
+}
+`
+
+func TestPlanFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		file  string
+		edits []Edit
+		want  string
+	}{
+		{
+			name:  "multi-line list keeps the comment of the last element",
+			file:  mainFile,
+			edits: []Edit{{File: "main.go", Kind: AddProvider, Code: "app.NewCache"}},
+			want: `		fx.Provide(
+			app.NewLogger,
+			app.NewServer, // serves HTTP
+			app.NewCache,
+		),
+`,
+		},
+		{
+			name:  "single-line list",
+			file:  "package main\n\nimport \"go.uber.org/fx\"\n\nfunc main() {\n\tfx.New(fx.Provide(app.NewLogger))\n}\n",
+			edits: []Edit{{File: "main.go", Kind: AddProvider, Code: "app.NewCache"}},
+			want:  "\tfx.New(fx.Provide(app.NewLogger, app.NewCache))\n",
+		},
+		{
+			name:  "empty list",
+			file:  "package main\n\nimport \"go.uber.org/fx\"\n\nfunc main() {\n\tfx.New(fx.Provide())\n}\n",
+			edits: []Edit{{File: "main.go", Kind: AddProvider, Code: "app.NewCache"}},
+			want:  "\tfx.New(fx.Provide(app.NewCache))\n",
+		},
+		{
+			name: "multi-line list ending in a block comment",
+			file: strings.Replace(mainFile, "// serves HTTP", "/* serves\n\t\t\tHTTP */", 1),
+			edits: []Edit{
+				{File: "main.go", Kind: AddProvider, Code: "app.NewCache"},
+			},
+			want: "\t\t\tHTTP */\n\t\t\tapp.NewCache,\n",
+		},
+		{
+			name: "parameters, call arguments and statements",
+			file: routerFile,
+			edits: []Edit{
+				{File: "main.go", Kind: AddParam, Func: "NewRouter", Code: "orderService OrderService"},
+				{File: "main.go", Kind: InsertStatements, Func: "NewRouter", Code: "\tr.Handle(\"/orders\", orderService)\n"},
+				{File: "main.go", Kind: AddParam, Func: "NewHandler", Code: "orderService OrderService"},
+				{File: "main.go", Kind: AddCallArg, Func: "NewHandler", Call: "NewRouter", Code: "orderService"},
+			},
+			want: `func NewRouter(userService UserService, orderService OrderService) Handler {
+	r := newMux()
+	r.Handle("/users", userService)
+
+	r.Handle("/orders", orderService)
+
+	return r
+}
+
+func NewHandler(userService UserService, orderService OrderService) Handler {
+	return NewRouter(userService, orderService)
+}
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := PlanFiles(map[string][]byte{"main.go": []byte(tc.file)}, tc.edits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(changes["main.go"])
+			if !strings.Contains(got, tc.want) {
+				t.Fatalf("got:\n%s\nwant it to contain:\n%s", got, tc.want)
+			}
+
+			// Applying the same edits again changes nothing
+			again, err := PlanFiles(map[string][]byte{"main.go": []byte(got)}, tc.edits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(again) > 0 {
+				t.Errorf("re-applying the edits changed the file:\n%s", again["main.go"])
+			}
+		})
+	}
+}
+
+func TestPlanFilesMissingAnchor(t *testing.T) {
+	files := map[string][]byte{"main.go": []byte(routerFile)}
+	for _, tc := range []struct {
+		edit   Edit
+		reason string
+	}{
+		{Edit{File: "main.go", Kind: AddProvider, Code: "app.NewCache"}, "go.uber.org/fx is not imported"},
+		{Edit{File: "main.go", Kind: AddParam, Func: "NewServer", Code: "s Server"}, "cannot find func NewServer"},
+		{Edit{File: "main.go", Kind: AddParam, Func: "NewRouter", Code: "userService OtherService"}, "NewRouter already has a parameter userService"},
+		{Edit{File: "main.go", Kind: AddCallArg, Func: "NewHandler", Call: "NewServer", Code: "s"}, "cannot find the call to NewServer"},
+		{Edit{File: "other.go", Kind: AddParam, Func: "NewRouter", Code: "s Server"}, "file not found"},
+	} {
+		// A valid edit is not applied when another one fails
+		edits := []Edit{{File: "main.go", Kind: AddParam, Func: "NewRouter", Code: "orderService OrderService"}, tc.edit}
+		changes, err := PlanFiles(files, edits)
+		var anchorErr *AnchorError
+		if !errors.As(err, &anchorErr) || !strings.HasPrefix(anchorErr.Reason, tc.reason) {
+			t.Errorf("%s: error = %v, want %q", tc.edit, err, tc.reason)
+			continue
+		}
+		if changes != nil {
+			t.Errorf("%s: changes were planned despite the error", tc.edit)
+		}
+		if !strings.Contains(err.Error(), "apply this change by hand") {
+			t.Errorf("%s: error does not explain the manual change:\n%v", tc.edit, err)
+		}
+	}
+}